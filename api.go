@@ -1,171 +1,167 @@
 package libtwitch
 
 import (
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
 	"net/url"
+	"strconv"
+	"time"
 )
 
-type TwitchClientError struct {
-	OriginalError error
-	message       string
+type User struct {
+	ID              string          `json:"id"`
+	Login           string          `json:"login"`
+	DisplayName     string          `json:"display_name"`
+	Type            UserType        `json:"type"`
+	BroadcasterType BroadcasterType `json:"broadcaster_type"`
+	Description     string          `json:"description"`
+	ProfileImageURL string          `json:"profile_image_url"`
+	OfflineImageURL string          `json:"offline_image_url"`
+	ViewCount       int             `json:"view_count"`
+	Email           string          `json:"email,omitempty"`
 }
 
-func (e *TwitchClientError) Error() string {
-	return e.message
+type Stream struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	UserLogin    string     `json:"user_login"`
+	UserName     string     `json:"user_name"`
+	GameID       string     `json:"game_id"`
+	GameName     string     `json:"game_name"`
+	Type         StreamType `json:"type"`
+	Title        string     `json:"title"`
+	Tags         []string   `json:"tags"`
+	ViewerCount  int        `json:"viewer_count"`
+	StartedAt    time.Time  `json:"started_at"`
+	Language     string     `json:"language"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	IsMature     bool       `json:"is_mature"`
 }
 
-func NewTwitchClientError(message string, originalError error) *TwitchClientError {
-	return &TwitchClientError{
-		message:       message,
-		OriginalError: originalError,
-	}
+// StreamsQuery filters GetStreams. All filters are optional, the lists accept up to 100 entries
+// each.
+type StreamsQuery struct {
+	UserIDs    []string
+	UserLogins []string
+	GameIDs    []string
+	Languages  []string
+	Type       StreamType // StreamTypeLive (default) or StreamTypeAll
+	First      int        // Page size, up to 100
+	After      string     // Cursor to resume from, see Paginator.Cursor
 }
 
-var ErrMultipleResults = NewTwitchClientError("multiple results found", nil)
-var ErrNotFound = NewTwitchClientError("not found", nil)
-
-type User struct {
-	ID              string `json:"id"`
-	Login           string `json:"login"`
-	DisplayName     string `json:"display_name"`
-	Type            string `json:"type"`
-	BroadcasterType string `json:"broadcaster_type"`
-	Description     string `json:"description"`
-	ProfileImageURL string `json:"profile_image_url"`
-	OfflineImageURL string `json:"offline_image_url"`
-	ViewCount       int    `json:"view_count"`
-	Email           string `json:"email,omitempty"`
-}
-
-type Stream struct {
-	ID           string   `json:"id"`
-	UserID       string   `json:"user_id"`
-	GameID       string   `json:"game_id"`
-	CommunityIDs []string `json:"community_ids"`
-	Type         string   `json:"type"`
-	Title        string   `json:"title"`
-	ViewerCount  int      `json:"viewer_count"`
-	StartedAt    string   `json:"started_at"`
-	Language     string   `json:"language"`
-	ThumbnailURL string   `json:"thumbnail_url"`
+func (q StreamsQuery) values() url.Values {
+	v := url.Values{}
+	if len(q.UserIDs) > 0 {
+		v["user_id"] = q.UserIDs
+	}
+	if len(q.UserLogins) > 0 {
+		v["user_login"] = q.UserLogins
+	}
+	if len(q.GameIDs) > 0 {
+		v["game_id"] = q.GameIDs
+	}
+	if len(q.Languages) > 0 {
+		v["language"] = q.Languages
+	}
+	if q.Type != "" {
+		v.Set("type", string(q.Type))
+	}
+	if q.First > 0 {
+		v.Set("first", strconv.Itoa(q.First))
+	}
+	return v
 }
 
 type Game struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	BoxArtURL string `json:"box_art_url"`
+	IGDBID    string `json:"igdb_id"`
 }
 
 type Follow struct {
-	FromID     string `json:"from_id"`
-	ToID       string `json:"to_id"`
-	FollowedAt string `json:"followed_at"`
+	FromID     string    `json:"from_id"`
+	ToID       string    `json:"to_id"`
+	FollowedAt time.Time `json:"followed_at"`
 }
 
-// TODO: copied a lot of code here, rethink this.
-func (c *TwitchClient) getUser(k, v string) (*User, error) {
-	resp, body, err := c.Request("GET", "users", &url.Values{k: []string{v}}, nil)
-	if err != nil {
-		return nil, NewTwitchClientError("error making request", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
-	}
+// getMany fetches the first page of results from a helix list endpoint, see Paginator for the rest.
+func getMany[T any](ctx context.Context, c *TwitchClient, path string, params url.Values) ([]T, error) {
+	return newPaginator[T](c, path, params).Next(ctx)
+}
 
-	u := []*User{}
-	err = json.Unmarshal(body, &u)
+// getOne fetches exactly one result from a helix list endpoint, returning ErrNotFound or
+// ErrMultipleResults otherwise.
+func getOne[T any](ctx context.Context, c *TwitchClient, path string, params url.Values) (T, error) {
+	var zero T
+	results, err := getMany[T](ctx, c, path, params)
 	if err != nil {
-		return nil, NewTwitchClientError("failed to parse response", err)
+		return zero, err
 	}
 
-	if len(u) == 0 {
-		return nil, ErrNotFound
+	if len(results) == 0 {
+		return zero, ErrNotFound
 	}
 
-	if len(u) > 1 {
-		return nil, ErrMultipleResults
+	if len(results) > 1 {
+		return zero, ErrMultipleResults
 	}
 
-	return u[0], nil
+	return results[0], nil
 }
 
-func (c *TwitchClient) GetUserByName(name string) (*User, error) {
-	return c.getUser("login", name)
+// GetUserByName looks up a user by login. With WithCache, results are cached.
+func (c *TwitchClient) GetUserByName(ctx context.Context, name string) (*User, error) {
+	return cachedLookup(c, userLoginKey(name), func() (*User, error) {
+		return getOne[*User](ctx, c, "users", url.Values{"login": []string{name}})
+	}, c.cacheUser)
 }
 
-func (c *TwitchClient) GetUserByID(id string) (*User, error) {
-	return c.getUser("id", id)
+// GetUserByID looks up a user by id. With WithBatching, concurrent calls are coalesced, with
+// WithCache results are cached.
+func (c *TwitchClient) GetUserByID(ctx context.Context, id string) (*User, error) {
+	return cachedLookup(c, userIDKey(id), func() (*User, error) {
+		if c.userLoader != nil {
+			return c.userLoader.load(ctx, id)
+		}
+		return getOne[*User](ctx, c, "users", url.Values{"id": []string{id}})
+	}, c.cacheUser)
 }
 
-func (c *TwitchClient) getGame(k, v string) (*Game, error) {
-	resp, body, err := c.Request("GET", "games", &url.Values{k: []string{v}}, nil)
-	if err != nil {
-		return nil, NewTwitchClientError("error making request", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
-	}
-
-	u := []*Game{}
-	err = json.Unmarshal(body, &u)
-	if err != nil {
-		return nil, NewTwitchClientError("failed to parse response", err)
-	}
-
-	if len(u) == 0 {
-		return nil, ErrNotFound
-	}
-
-	if len(u) > 1 {
-		return nil, ErrMultipleResults
-	}
-
-	return u[0], nil
+// GetGameByName looks up a game by name. With WithCache, results are cached.
+func (c *TwitchClient) GetGameByName(ctx context.Context, name string) (*Game, error) {
+	return cachedLookup(c, gameNameKey(name), func() (*Game, error) {
+		return getOne[*Game](ctx, c, "games", url.Values{"name": []string{name}})
+	}, c.cacheGame)
 }
 
-func (c *TwitchClient) GetGameByName(name string) (*Game, error) {
-	return c.getGame("name", name)
+// GetGameByID looks up a game by id. With WithBatching, concurrent calls are coalesced, with
+// WithCache results are cached.
+func (c *TwitchClient) GetGameByID(ctx context.Context, id string) (*Game, error) {
+	return cachedLookup(c, gameIDKey(id), func() (*Game, error) {
+		if c.gameLoader != nil {
+			return c.gameLoader.load(ctx, id)
+		}
+		return getOne[*Game](ctx, c, "games", url.Values{"id": []string{id}})
+	}, c.cacheGame)
 }
 
-func (c *TwitchClient) GetGameByID(id string) (*Game, error) {
-	return c.getGame("id", id)
+// GetTopGames lists games and categories by current viewer count, most viewed first.
+func (c *TwitchClient) GetTopGames(ctx context.Context) *Paginator[*Game] {
+	return newPaginator[*Game](c, "games/top", url.Values{"first": []string{strconv.Itoa(maxPageSize)}}).bind(ctx)
 }
 
-func (c *TwitchClient) getStream(k, v string) (*Stream, error) {
-	resp, body, err := c.Request("GET", "streams", &url.Values{k: []string{v}}, nil)
-	if err != nil {
-		return nil, NewTwitchClientError("error making request", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
-	}
-
-	u := []*Stream{}
-	err = json.Unmarshal(body, &u)
-	if err != nil {
-		return nil, NewTwitchClientError("failed to parse response", err)
-	}
-
-	if len(u) == 0 {
-		return nil, ErrNotFound
-	}
-
-	if len(u) > 1 {
-		return nil, ErrMultipleResults
-	}
-
-	return u[0], nil
+func (c *TwitchClient) GetStreamByUserName(ctx context.Context, name string) (*Stream, error) {
+	return getOne[*Stream](ctx, c, "streams", url.Values{"user_login": []string{name}})
 }
 
-func (c *TwitchClient) GetStreamByUserName(name string) (*Stream, error) {
-	return c.getStream("user_login", name)
+func (c *TwitchClient) GetStreamByUserID(ctx context.Context, id string) (*Stream, error) {
+	return getOne[*Stream](ctx, c, "streams", url.Values{"user_id": []string{id}})
 }
 
-func (c *TwitchClient) GetStreamByUserID(id string) (*Stream, error) {
-	return c.getStream("user_id", id)
+// GetStreams lists live streams (most viewers first) matching the query.
+func (c *TwitchClient) GetStreams(ctx context.Context, q StreamsQuery) *Paginator[*Stream] {
+	p := newPaginator[*Stream](c, "streams", q.values()).bind(ctx)
+	p.cursor = q.After
+	return p
 }
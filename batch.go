@@ -0,0 +1,149 @@
+package libtwitch
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxBatchSize is the most ids/logins helix accepts in a single request.
+	maxBatchSize = 100
+
+	// batchConcurrency is how many chunks of a batch lookup are requested at once. The rate
+	// limiter still applies to each of them.
+	batchConcurrency = 4
+)
+
+// BatchResult holds the results of a batch lookup keyed by the requested id (or login), and the
+// ones twitch didn't return anything for.
+type BatchResult[T any] struct {
+	Found   map[string]T
+	Missing []string
+}
+
+// batchLookup looks up keys in chunks of maxBatchSize, passing them as repeated param values
+// along with any extra params. keyOf maps a result back to the key it was requested by.
+//
+// Endpoints that return one result per key (users, games, channels) answer a whole chunk at once
+// and don't take "first"; list endpoints like streams need first=maxBatchSize in extra to do so.
+func batchLookup[T any](ctx context.Context, c *TwitchClient, path, param string, keys []string, extra url.Values, keyOf func(T) string) (*BatchResult[T], error) {
+	unique := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, k)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := &BatchResult[T]{Found: make(map[string]T, len(unique))}
+	var mtx sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchConcurrency)
+
+	for start := 0; start < len(unique); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		chunk := unique[start:end]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			params := url.Values{param: chunk}
+			for k, v := range extra {
+				params[k] = v
+			}
+			results, err := getMany[T](ctx, c, path, params)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			for _, r := range results {
+				result.Found[keyOf(r)] = r
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for _, k := range unique {
+		if _, ok := result.Found[k]; !ok {
+			result.Missing = append(result.Missing, k)
+		}
+	}
+	return result, nil
+}
+
+// GetUsers looks up any number of users by id. With WithCache, cached users are served without a
+// request and the results are cached.
+func (c *TwitchClient) GetUsers(ctx context.Context, ids []string) (*BatchResult[*User], error) {
+	return cachedBatchLookup(c, ids, userIDKey, func(ids []string) (*BatchResult[*User], error) {
+		return c.lookupUsers(ctx, ids)
+	}, c.cacheUser)
+}
+
+// lookupUsers is GetUsers without the cache, for the user loader whose callers already check it.
+func (c *TwitchClient) lookupUsers(ctx context.Context, ids []string) (*BatchResult[*User], error) {
+	return batchLookup(ctx, c, "users", "id", ids, nil, func(u *User) string { return u.ID })
+}
+
+// GetUsersByName looks up any number of users by login. Logins are matched case-insensitively,
+// results are keyed by the lowercase login. With WithCache, results are cached.
+func (c *TwitchClient) GetUsersByName(ctx context.Context, logins []string) (*BatchResult[*User], error) {
+	lower := make([]string, len(logins))
+	for i, login := range logins {
+		lower[i] = strings.ToLower(login)
+	}
+	return cachedBatchLookup(c, lower, userLoginKey, func(logins []string) (*BatchResult[*User], error) {
+		return batchLookup(ctx, c, "users", "login", logins, nil, func(u *User) string { return strings.ToLower(u.Login) })
+	}, c.cacheUser)
+}
+
+// GetGames looks up any number of games by id. With WithCache, cached games are served without a
+// request and the results are cached.
+func (c *TwitchClient) GetGames(ctx context.Context, ids []string) (*BatchResult[*Game], error) {
+	return cachedBatchLookup(c, ids, gameIDKey, func(ids []string) (*BatchResult[*Game], error) {
+		return c.lookupGames(ctx, ids)
+	}, c.cacheGame)
+}
+
+// lookupGames is GetGames without the cache, for the game loader whose callers already check it.
+func (c *TwitchClient) lookupGames(ctx context.Context, ids []string) (*BatchResult[*Game], error) {
+	return batchLookup(ctx, c, "games", "id", ids, nil, func(g *Game) string { return g.ID })
+}
+
+// GetStreamsByUserIDs looks up the live streams of any number of users. Users who aren't live end
+// up in Missing.
+func (c *TwitchClient) GetStreamsByUserIDs(ctx context.Context, userIDs []string) (*BatchResult[*Stream], error) {
+	return batchLookup(ctx, c, "streams", "user_id", userIDs, url.Values{"first": []string{strconv.Itoa(maxBatchSize)}}, func(s *Stream) string { return s.UserID })
+}
@@ -0,0 +1,80 @@
+package libtwitch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// usersHandler answers user lookups by id with one user per id, except for ids starting with
+// "missing", and counts the requests.
+func usersHandler(requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		users := []string{}
+		for _, id := range r.URL.Query()["id"] {
+			if !strings.HasPrefix(id, "missing") {
+				users = append(users, fmt.Sprintf(`{"id":%q,"login":"user%s"}`, id, id))
+			}
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(users, ","))
+	}
+}
+
+func TestBatchLookupChunks(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, usersHandler(&requests))
+
+	ids := []string{"missing-1"}
+	for i := 0; i < 250; i++ {
+		ids = append(ids, strconv.Itoa(i), strconv.Itoa(i))
+	}
+	result, err := c.GetUsers(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Found) != 250 {
+		t.Errorf("expected 250 users, got %d", len(result.Found))
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "missing-1" {
+		t.Errorf("unexpected missing ids: %v", result.Missing)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests for 251 unique ids, got %d", got)
+	}
+}
+
+func TestBatchLookupFirstParam(t *testing.T) {
+	firsts := map[string]string{}
+	var mtx sync.Mutex
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		firsts[r.URL.Path] = r.URL.Query().Get("first")
+		mtx.Unlock()
+		fmt.Fprint(w, `{"data":[]}`)
+	}))
+
+	ctx := context.Background()
+	if _, err := c.GetUsers(ctx, []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetGames(ctx, []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStreamsByUserIDs(ctx, []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the streams list needs first to return a result for every requested user.
+	expected := map[string]string{"/users": "", "/games": "", "/streams": "100"}
+	for path, first := range expected {
+		if firsts[path] != first {
+			t.Errorf("expected first=%q for %s, got %q", first, path, firsts[path])
+		}
+	}
+}
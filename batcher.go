@@ -0,0 +1,104 @@
+package libtwitch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithBatching coalesces GetUserByID and GetGameByID calls made within window of each other
+// into batched requests (up to 100 ids each), trading a little latency for far fewer requests
+// during bursts.
+func WithBatching(window time.Duration) Option {
+	return func(c *TwitchClient) {
+		c.batchWindow = window
+	}
+}
+
+// loader collects concurrent single-key lookups and resolves them with one batch lookup.
+type loader[T any] struct {
+	client *TwitchClient
+	window time.Duration
+	fetch  func(ctx context.Context, keys []string) (*BatchResult[T], error)
+
+	mtx     sync.Mutex
+	pending *loaderBatch[T]
+}
+
+type loaderBatch[T any] struct {
+	keys []string
+	seen map[string]bool
+
+	done   chan struct{}
+	result *BatchResult[T]
+	err    error
+}
+
+func newLoader[T any](c *TwitchClient, window time.Duration, fetch func(context.Context, []string) (*BatchResult[T], error)) *loader[T] {
+	return &loader[T]{
+		client: c,
+		window: window,
+		fetch:  fetch,
+	}
+}
+
+// load queues key for the next batch and waits for its result, returning ErrNotFound if twitch
+// didn't return anything for it.
+func (l *loader[T]) load(ctx context.Context, key string) (T, error) {
+	l.mtx.Lock()
+	b := l.pending
+	if b == nil {
+		b = &loaderBatch[T]{
+			seen: make(map[string]bool),
+			done: make(chan struct{}),
+		}
+		l.pending = b
+		time.AfterFunc(l.window, func() { l.dispatch(b) })
+	}
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+	if len(b.keys) >= maxBatchSize {
+		l.pending = nil
+		go l.run(b)
+	}
+	l.mtx.Unlock()
+
+	var zero T
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+
+	if b.err != nil {
+		return zero, b.err
+	}
+	v, ok := b.result.Found[key]
+	if !ok {
+		return zero, ErrNotFound
+	}
+	return v, nil
+}
+
+// dispatch runs the batch when its window is over, unless it already ran because it filled up.
+func (l *loader[T]) dispatch(b *loaderBatch[T]) {
+	l.mtx.Lock()
+	if l.pending != b {
+		l.mtx.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mtx.Unlock()
+
+	l.run(b)
+}
+
+// run fetches the batch. It is bound to the client's lifetime since it serves several callers,
+// each of which can still give up on its own.
+func (l *loader[T]) run(b *loaderBatch[T]) {
+	l.client.log("batcher: looking up %d keys", len(b.keys))
+	b.result, b.err = l.fetch(l.client.ctx, b.keys)
+	close(b.done)
+}
@@ -0,0 +1,94 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoaderCoalescesLookups(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, usersHandler(&requests), WithBatching(50*time.Millisecond))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 150; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			u, err := c.GetUserByID(context.Background(), id)
+			if err != nil {
+				t.Errorf("GetUserByID(%s): %s", id, err)
+				return
+			}
+			if u.ID != id {
+				t.Errorf("GetUserByID(%s) returned user %s", id, u.ID)
+			}
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+
+	// 100 ids fill the first batch right away, the other 50 go out when the window closes.
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected 2 batched requests, got %d", got)
+	}
+}
+
+func TestLoaderMissingKey(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, usersHandler(&requests), WithBatching(20*time.Millisecond))
+
+	var wg sync.WaitGroup
+	var foundErr, missingErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, foundErr = c.GetUserByID(context.Background(), "1")
+	}()
+	go func() {
+		defer wg.Done()
+		_, missingErr = c.GetUserByID(context.Background(), "missing")
+	}()
+	wg.Wait()
+
+	if foundErr != nil {
+		t.Errorf("unexpected error: %s", foundErr)
+	}
+	if !errors.Is(missingErr, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", missingErr)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 batched request, got %d", got)
+	}
+}
+
+func TestLoaderCallerCancel(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, usersHandler(&requests), WithBatching(50*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var wg sync.WaitGroup
+	var canceledErr, err error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, canceledErr = c.GetUserByID(ctx, "1")
+	}()
+	go func() {
+		defer wg.Done()
+		_, err = c.GetUserByID(context.Background(), "2")
+	}()
+	wg.Wait()
+
+	if !errors.Is(canceledErr, context.Canceled) {
+		t.Errorf("expected the canceled caller to give up, got %v", canceledErr)
+	}
+	if err != nil {
+		t.Errorf("expected the other caller to succeed, got %v", err)
+	}
+}
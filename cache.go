@@ -0,0 +1,254 @@
+package libtwitch
+
+import (
+	"container/list"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores lookup results, see WithCache. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+}
+
+// CacheTTLs configures how long each kind of lookup result is cached. NotFound applies to
+// lookups that returned ErrNotFound. A zero TTL disables caching of that kind.
+type CacheTTLs struct {
+	Users    time.Duration
+	Games    time.Duration
+	NotFound time.Duration
+}
+
+var DefaultCacheTTLs = CacheTTLs{
+	Users:    time.Hour,
+	Games:    24 * time.Hour,
+	NotFound: 5 * time.Minute,
+}
+
+// CacheStats counts cache lookups. NegativeHits are hits on cached ErrNotFound results and are
+// included in Hits.
+type CacheStats struct {
+	Hits         uint64
+	Misses       uint64
+	NegativeHits uint64
+}
+
+// cacheCounters backs CacheStats. atomic.Uint64 stays aligned on 32-bit platforms, unlike plain
+// uint64 fields used with atomic.AddUint64.
+type cacheCounters struct {
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	negativeHits atomic.Uint64
+}
+
+// WithCache caches user and game lookups (by id as well as by login/name) in the given cache.
+func WithCache(cache Cache, ttls CacheTTLs) Option {
+	return func(c *TwitchClient) {
+		c.cache = cache
+		c.cacheTTLs = ttls
+	}
+}
+
+// CacheStats returns the client's cache hit/miss counters.
+func (c *TwitchClient) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:         c.cacheStats.hits.Load(),
+		Misses:       c.cacheStats.misses.Load(),
+		NegativeHits: c.cacheStats.negativeHits.Load(),
+	}
+}
+
+// notFound is cached for lookups that returned ErrNotFound.
+type notFound struct{}
+
+func userIDKey(id string) string       { return "user:id:" + id }
+func userLoginKey(login string) string { return "user:login:" + strings.ToLower(login) }
+func gameIDKey(id string) string       { return "game:id:" + id }
+func gameNameKey(name string) string   { return "game:name:" + strings.ToLower(name) }
+
+// cachedLookup serves key from the cache, or calls fetch and caches the result. store caches a
+// fetched value under all of its keys, so e.g. a lookup by id also populates the lookup by login.
+func cachedLookup[T any](c *TwitchClient, key string, fetch func() (T, error), store func(T)) (T, error) {
+	var zero T
+	if c.cache == nil {
+		return fetch()
+	}
+
+	if v, ok := c.cache.Get(key); ok {
+		switch v := v.(type) {
+		case notFound:
+			c.cacheStats.hits.Add(1)
+			c.cacheStats.negativeHits.Add(1)
+			return zero, ErrNotFound
+		case T:
+			c.cacheStats.hits.Add(1)
+			return v, nil
+		}
+	}
+	c.cacheStats.misses.Add(1)
+
+	v, err := fetch()
+	switch {
+	case err == nil:
+		store(v)
+	case errors.Is(err, ErrNotFound) && c.cacheTTLs.NotFound > 0:
+		c.cache.Set(key, notFound{}, c.cacheTTLs.NotFound)
+	}
+	return v, err
+}
+
+// cachedBatchLookup serves the keys it can from the cache and batch looks up the rest with
+// fetch, caching what it returns through store and what it doesn't as not found. keyOf maps a
+// requested key to its cache key.
+func cachedBatchLookup[T any](c *TwitchClient, keys []string, keyOf func(string) string, fetch func([]string) (*BatchResult[T], error), store func(T)) (*BatchResult[T], error) {
+	if c.cache == nil {
+		return fetch(keys)
+	}
+
+	result := &BatchResult[T]{Found: make(map[string]T, len(keys))}
+	seen := make(map[string]bool, len(keys))
+	var unique, uncached []string
+	for _, k := range keys {
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, k)
+
+		if v, ok := c.cache.Get(keyOf(k)); ok {
+			switch v := v.(type) {
+			case notFound:
+				c.cacheStats.hits.Add(1)
+				c.cacheStats.negativeHits.Add(1)
+				continue
+			case T:
+				c.cacheStats.hits.Add(1)
+				result.Found[k] = v
+				continue
+			}
+		}
+		c.cacheStats.misses.Add(1)
+		uncached = append(uncached, k)
+	}
+
+	if len(uncached) > 0 {
+		fetched, err := fetch(uncached)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fetched.Found {
+			result.Found[k] = v
+			store(v)
+		}
+		if c.cacheTTLs.NotFound > 0 {
+			for _, k := range fetched.Missing {
+				c.cache.Set(keyOf(k), notFound{}, c.cacheTTLs.NotFound)
+			}
+		}
+	}
+
+	for _, k := range unique {
+		if _, ok := result.Found[k]; !ok {
+			result.Missing = append(result.Missing, k)
+		}
+	}
+	return result, nil
+}
+
+func (c *TwitchClient) cacheUser(u *User) {
+	if c.cache == nil || c.cacheTTLs.Users <= 0 {
+		return
+	}
+	c.cache.Set(userIDKey(u.ID), u, c.cacheTTLs.Users)
+	c.cache.Set(userLoginKey(u.Login), u, c.cacheTTLs.Users)
+}
+
+func (c *TwitchClient) cacheGame(g *Game) {
+	if c.cache == nil || c.cacheTTLs.Games <= 0 {
+		return
+	}
+	c.cache.Set(gameIDKey(g.ID), g, c.cacheTTLs.Games)
+	c.cache.Set(gameNameKey(g.Name), g, c.cacheTTLs.Games)
+}
+
+// LRUCache is an in-memory Cache holding up to a fixed number of entries, evicting the least
+// recently used ones.
+type LRUCache struct {
+	size    int
+	mtx     sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (l *LRUCache) Get(key string) (interface{}, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.remove(e)
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+	return entry.value, true
+}
+
+func (l *LRUCache) Set(key string, value interface{}, ttl time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	entry := &lruEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+	if e, ok := l.entries[key]; ok {
+		e.Value = entry
+		l.order.MoveToFront(e)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(entry)
+	for l.size > 0 && l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *LRUCache) Delete(key string) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if e, ok := l.entries[key]; ok {
+		l.remove(e)
+	}
+}
+
+// Len returns the number of cached entries, including expired ones not evicted yet.
+func (l *LRUCache) Len() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRUCache) remove(e *list.Element) {
+	l.order.Remove(e)
+	delete(l.entries, e.Value.(*lruEntry).key)
+}
@@ -0,0 +1,147 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	l := NewLRUCache(2)
+	l.Set("a", 1, time.Minute)
+	l.Set("b", 2, time.Minute)
+	if _, ok := l.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	l.Set("c", 3, time.Minute)
+
+	if _, ok := l.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if v, ok := l.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a to survive, got %v %v", v, ok)
+	}
+	if l.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", l.Len())
+	}
+
+	l.Delete("a")
+	if _, ok := l.Get("a"); ok {
+		t.Error("expected a to be deleted")
+	}
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	l := NewLRUCache(0)
+	l.Set("a", 1, 10*time.Millisecond)
+	l.Set("b", 2, time.Minute)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := l.Get("a"); ok {
+		t.Error("expected a to expire")
+	}
+	if _, ok := l.Get("b"); !ok {
+		t.Error("expected b to still be cached")
+	}
+}
+
+func TestCachedUserLookups(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		q := r.URL.Query()
+		if q.Get("id") == "1" || q.Get("login") == "foo" {
+			fmt.Fprint(w, `{"data":[{"id":"1","login":"foo"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[]}`)
+	}), WithCache(NewLRUCache(10), DefaultCacheTTLs))
+	ctx := context.Background()
+
+	if _, err := c.GetUserByID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	// Cached under both the id and the login by the first lookup.
+	if _, err := c.GetUserByID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := c.GetUserByName(ctx, "FOO"); err != nil || u.ID != "1" {
+		t.Fatalf("unexpected lookup by name: %v %v", u, err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetUserByID(ctx, "2"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected the miss to be cached, got %d requests", got)
+	}
+
+	want := CacheStats{Hits: 3, Misses: 2, NegativeHits: 1}
+	if got := c.CacheStats(); got != want {
+		t.Errorf("expected stats %+v, got %+v", want, got)
+	}
+}
+
+func TestCachedBatchLookups(t *testing.T) {
+	var mtx sync.Mutex
+	var requested [][]string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query()["id"]
+		mtx.Lock()
+		requested = append(requested, ids)
+		mtx.Unlock()
+
+		users := []string{}
+		for _, id := range ids {
+			if id != "3" {
+				users = append(users, fmt.Sprintf(`{"id":%q,"login":"user%s"}`, id, id))
+			}
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(users, ","))
+	}), WithCache(NewLRUCache(10), DefaultCacheTTLs))
+	ctx := context.Background()
+
+	if _, err := c.GetUserByID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.GetUsers(ctx, []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Found) != 2 || !reflect.DeepEqual(result.Missing, []string{"3"}) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// Everything is cached now, including the missing user.
+	result, err = c.GetUsers(ctx, []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Found) != 2 || !reflect.DeepEqual(result.Missing, []string{"3"}) {
+		t.Fatalf("unexpected cached result: %+v", result)
+	}
+	if u, err := c.GetUserByName(ctx, "user2"); err != nil || u.ID != "2" {
+		t.Fatalf("expected user 2 to be cached by login, got %v %v", u, err)
+	}
+
+	want := [][]string{{"1"}, {"2", "3"}}
+	if !reflect.DeepEqual(requested, want) {
+		t.Errorf("expected requests for %v, got %v", want, requested)
+	}
+	wantStats := CacheStats{Hits: 5, Misses: 3, NegativeHits: 1}
+	if got := c.CacheStats(); got != wantStats {
+		t.Errorf("expected stats %+v, got %+v", wantStats, got)
+	}
+}
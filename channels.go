@@ -0,0 +1,102 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Channel struct {
+	BroadcasterID               string   `json:"broadcaster_id"`
+	BroadcasterLogin            string   `json:"broadcaster_login"`
+	BroadcasterName             string   `json:"broadcaster_name"`
+	BroadcasterLanguage         string   `json:"broadcaster_language"`
+	GameID                      string   `json:"game_id"`
+	GameName                    string   `json:"game_name"`
+	Title                       string   `json:"title"`
+	Delay                       int      `json:"delay"`
+	Tags                        []string `json:"tags"`
+	ContentClassificationLabels []string `json:"content_classification_labels"`
+	IsBrandedContent            bool     `json:"is_branded_content"`
+}
+
+// ContentClassificationLabel enables or disables a content classification label (e.g.
+// "MatureGame", "ProfanityVulgarity") on a channel.
+type ContentClassificationLabel struct {
+	ID        string `json:"id"`
+	IsEnabled bool   `json:"is_enabled"`
+}
+
+// ChannelUpdate holds the channel properties to change with ModifyChannelInformation. Nil fields
+// are left unchanged. Set GameID to "0" or "" to unset the category, and Tags to an empty
+// (non-nil) slice to remove all tags.
+type ChannelUpdate struct {
+	GameID                      *string
+	BroadcasterLanguage         *string
+	Title                       *string
+	Delay                       *int // Partners only
+	Tags                        []string
+	ContentClassificationLabels []ContentClassificationLabel
+	IsBrandedContent            *bool
+}
+
+func (u ChannelUpdate) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	if u.GameID != nil {
+		m["game_id"] = *u.GameID
+	}
+	if u.BroadcasterLanguage != nil {
+		m["broadcaster_language"] = *u.BroadcasterLanguage
+	}
+	if u.Title != nil {
+		m["title"] = *u.Title
+	}
+	if u.Delay != nil {
+		m["delay"] = *u.Delay
+	}
+	if u.Tags != nil {
+		m["tags"] = u.Tags
+	}
+	if u.ContentClassificationLabels != nil {
+		m["content_classification_labels"] = u.ContentClassificationLabels
+	}
+	if u.IsBrandedContent != nil {
+		m["is_branded_content"] = *u.IsBrandedContent
+	}
+	return json.Marshal(m)
+}
+
+// GetChannelInformation looks up the channels of the given broadcasters, in the order given.
+// Unknown broadcasters are skipped.
+func (c *TwitchClient) GetChannelInformation(ctx context.Context, broadcasterIDs ...string) ([]*Channel, error) {
+	result, err := batchLookup(ctx, c, "channels", "broadcaster_id", broadcasterIDs, nil, func(ch *Channel) string { return ch.BroadcasterID })
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]*Channel, 0, len(result.Found))
+	for _, id := range broadcasterIDs {
+		if ch, ok := result.Found[id]; ok {
+			channels = append(channels, ch)
+			delete(result.Found, id)
+		}
+	}
+	return channels, nil
+}
+
+// ModifyChannelInformation updates a channel's title, category and other properties. It requires
+// a user token of the broadcaster (or an editor) with the channel:manage:broadcast scope.
+func (c *TwitchClient) ModifyChannelInformation(ctx context.Context, broadcasterID string, update ChannelUpdate) error {
+	params := &url.Values{"broadcaster_id": []string{broadcasterID}}
+	resp, _, err := c.RequestContext(ctx, "PATCH", "channels", params, update)
+	if err != nil {
+		return NewTwitchClientError("error making request", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		return NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
+	}
+	return nil
+}
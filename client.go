@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io/ioutil"
 	logger "log"
 	"net/http"
@@ -19,80 +17,109 @@ import (
 var log = logger.New(os.Stdout, "libtwitch: ", 0)
 
 const (
-	oAuthTokenEndpoint = "https://id.twitch.tv/oauth2/token"
-	apiEndpoint        = "https://api.twitch.tv/helix/"
-)
+	oAuthEndpoint = "https://id.twitch.tv/oauth2/"
+	apiEndpoint   = "https://api.twitch.tv/helix/"
 
-type AccessToken struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int    `json:"refresh_token"`
-	Scope        string `json:"scope"`
+	defaultUserAgent = "libtwitch/v1"
+	defaultTimeout   = 10 * time.Second
+	defaultLease     = 10 * time.Minute
+)
 
-	ExpiresAt time.Time
+type Response struct {
+	Data       json.RawMessage `json:"data"`
+	Pagination Pagination      `json:"pagination"`
+	Total      int             `json:"total"`
 }
 
-type Response struct {
-	Data json.RawMessage `json:"data"`
+// Pagination holds the cursor for the next page of a list endpoint, see Paginator.
+type Pagination struct {
+	Cursor string `json:"cursor"`
 }
 
 type TwitchClient struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	endpoint     string
-	clientID     string
-	clientSecret string
+	endpoint      string
+	oauthEndpoint string
+	clientID      string
+	clientSecret  string
+	userAgent     string
+
+	token      *AccessToken
+	tokenFetch *tokenFetch
+	userMode   bool
+	tokenMtx   sync.Mutex
+
+	tokenStore  TokenStore
+	tokenUserID string
 
-	token    *AccessToken
-	tokenMtx sync.Mutex
+	ratelimit   rateLimiter
+	retryPolicy RetryPolicy
+
+	batchWindow time.Duration
+	userLoader  *loader[*User]
+	gameLoader  *loader[*Game]
+
+	cache      Cache
+	cacheTTLs  CacheTTLs
+	cacheStats cacheCounters
 
 	callbackURL       string
 	callbackSecret    string
+	lease             time.Duration
 	streamWatchers    map[string]*StreamWatcher
 	streamWatchersMtx sync.Mutex
 
-	client http.Client
+	client *http.Client
+	logger *logger.Logger
 	debug  bool
 }
 
-// NewTwitchClient makes a twitch API client using the OAuth client credentials flow.
-func NewTwitchClient(ctx context.Context, clientID, clientSecret, callbackURL string, debug bool) (*TwitchClient, error) {
-	timeout := time.Duration(10 * time.Second)
-	client := http.Client{
-		Timeout: timeout,
-	}
+// NewTwitchClient makes a twitch API client. With only a client id it sends the Client-ID header,
+// configuring a client secret (see WithClientSecret) switches to the OAuth client credentials flow.
+func NewTwitchClient(ctx context.Context, clientID string, opts ...Option) (*TwitchClient, error) {
+	c := &TwitchClient{
+		endpoint:      apiEndpoint,
+		oauthEndpoint: oAuthEndpoint,
+		clientID:      clientID,
+		userAgent:     defaultUserAgent,
 
-	secret, err := makeSecret()
-	if err != nil {
-		return nil, err
-	}
+		retryPolicy: DefaultRetryPolicy,
+
+		lease:          defaultLease,
+		streamWatchers: make(map[string]*StreamWatcher),
 
-	cctx, cancel := context.WithCancel(ctx)
+		client: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		logger: log,
+	}
 
-	return &TwitchClient{
-		ctx:    cctx,
-		cancel: cancel,
+	for _, opt := range opts {
+		opt(c)
+	}
 
-		endpoint:     apiEndpoint,
-		clientID:     clientID,
-		clientSecret: clientSecret,
+	if c.callbackSecret == "" {
+		secret, err := makeSecret()
+		if err != nil {
+			return nil, err
+		}
+		c.callbackSecret = secret
+	}
 
-		callbackURL:    callbackURL,
-		callbackSecret: secret,
-		streamWatchers: make(map[string]*StreamWatcher),
+	c.ctx, c.cancel = context.WithCancel(ctx)
 
-		client: client,
-		debug:  debug,
-	}, nil
+	if c.batchWindow > 0 {
+		c.userLoader = newLoader(c, c.batchWindow, c.lookupUsers)
+		c.gameLoader = newLoader(c, c.batchWindow, c.lookupGames)
+	}
+	return c, nil
 }
 
 func (c *TwitchClient) log(format string, v ...interface{}) {
 	if c.debug {
-		if v != nil {
-			format = fmt.Sprintf(format, v)
-		}
-		log.Println(format)
+		c.logger.Printf(format, v...)
 	}
 }
 
@@ -103,7 +130,7 @@ func (c *TwitchClient) Close() {
 func (c *TwitchClient) doRequest(request *http.Request) (*http.Response, []byte, error) {
 	if c.debug {
 		dump, _ := httputil.DumpRequest(request, true)
-		fmt.Printf("\nREQUEST:\n%s\n\n", dump)
+		c.logger.Printf("\nREQUEST:\n%s\n\n", dump)
 	}
 
 	resp, err := c.client.Do(request)
@@ -122,122 +149,172 @@ func (c *TwitchClient) doRequest(request *http.Request) (*http.Response, []byte,
 
 	if c.debug {
 		dump, _ := httputil.DumpResponse(resp, false)
-		fmt.Printf("\nRESPONSE:\n%s%s\n\n", dump, string(body))
+		c.logger.Printf("\nRESPONSE:\n%s%s\n\n", dump, string(body))
 	}
 
 	return resp, body, nil
 }
 
-// authenticate will fetch an OAuth2 access token with the given clientID and clientSecret, and
-// add it as an "Authorization" header to the given request.
-func (c *TwitchClient) authenticate(request *http.Request) error {
-
-	if c.clientID == "" {
-		return errors.New("client id is required")
-	}
-
-	if c.clientSecret == "" {
-		c.log("auth: client secret not configured, using client id only")
-		request.Header.Set("Client-ID", c.clientID)
-		return nil
-	}
-
-	c.tokenMtx.Lock()
-	defer c.tokenMtx.Unlock()
-
-	if c.token != nil && c.token.ExpiresAt.Before(time.Now()) {
-		c.log("using cached access token (expires: %s)", c.token.ExpiresAt)
-		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token.AccessToken))
-		return nil
+func (c *TwitchClient) marshalResponse(b []byte) (*Response, error) {
+	response := &Response{}
+	if len(b) > 0 {
+		err := json.Unmarshal(b, response)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return response, nil
+}
 
-	v := url.Values{
-		"client_id":     []string{c.clientID},
-		"client_secret": []string{c.clientSecret},
-		"grant_type":    []string{"client_credentials"},
-	}
+// Request makes an API request bound to the client's lifetime, see RequestContext.
+func (c *TwitchClient) Request(method string, path string, params *url.Values, body interface{}) (*http.Response, []byte, error) {
+	return c.RequestContext(c.ctx, method, path, params, body)
+}
 
-	authRequest, err := http.NewRequest("POST", oAuthTokenEndpoint+"?"+v.Encode(), nil)
+// RequestContext makes an authenticated API request and returns the response along with the
+// contents of the "data" field, see RequestPage.
+func (c *TwitchClient) RequestContext(ctx context.Context, method string, path string, params *url.Values, body interface{}) (*http.Response, []byte, error) {
+	resp, response, err := c.RequestPage(ctx, method, path, params, body)
 	if err != nil {
-		return err
+		return resp, nil, err
 	}
+	return resp, response.Data, nil
+}
 
-	c.log("requesting new access token")
-	resp, body, err := c.doRequest(authRequest)
+// RequestPage makes an authenticated API request and returns the response along with the parsed
+// body, including pagination info. See RequestRaw for error handling.
+func (c *TwitchClient) RequestPage(ctx context.Context, method string, path string, params *url.Values, body interface{}) (*http.Response, *Response, error) {
+	resp, b, err := c.RequestRaw(ctx, method, path, params, body)
 	if err != nil {
-		return err
+		return resp, nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("authentication failed: HTTP %d", resp.StatusCode)
-	}
-
-	t := &AccessToken{}
-	err = json.Unmarshal(body, t)
+	response, err := c.marshalResponse(b)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-
-	t.ExpiresAt = time.Now().Add(time.Second * time.Duration(t.ExpiresIn))
-	c.token = t
-	c.log("using new access token (expires: %s)", c.token.ExpiresAt)
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token.AccessToken))
-	return nil
+	return resp, response, nil
 }
 
-func (c *TwitchClient) marshalResponse(b []byte) (*Response, error) {
-	response := &Response{}
-	if len(b) > 0 {
-		err := json.Unmarshal(b, response)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return response, nil
+// RequestRaw makes an authenticated API request and returns the response along with the unparsed
+// body, for endpoints that don't return JSON. The request is canceled when ctx is done. Error
+// responses are returned as an *APIError, requests the token lacks the OAuth scopes for fail with
+// an *ErrMissingScope without being sent.
+func (c *TwitchClient) RequestRaw(ctx context.Context, method string, path string, params *url.Values, body interface{}) (*http.Response, []byte, error) {
+	return c.requestRaw(ctx, method, path, params, body, isIdempotent(method))
 }
 
-func (c *TwitchClient) Request(method string, path string, params *url.Values, body interface{}) (*http.Response, []byte, error) {
+// requestRaw is RequestRaw for callers that know better than the method whether the request is
+// safe to retry.
+func (c *TwitchClient) requestRaw(ctx context.Context, method string, path string, params *url.Values, body interface{}, idempotent bool) (*http.Response, []byte, error) {
+
+	err := c.checkScopes(method, path)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	endpoint := c.endpoint + path
 	if params != nil {
 		endpoint = endpoint + "?" + params.Encode()
 	}
 
-	var bb *bytes.Buffer
+	var bb []byte
 	if body != nil {
-		bb = bytes.NewBuffer(nil)
-		enc := json.NewEncoder(bb)
+		buf := bytes.NewBuffer(nil)
+		enc := json.NewEncoder(buf)
 		enc.SetEscapeHTML(false)
 		err := enc.Encode(body)
 		if err != nil {
 			return nil, nil, err
 		}
-	} else {
-		bb = bytes.NewBuffer(nil)
+		bb = buf.Bytes()
 	}
 
-	request, err := http.NewRequest(method, endpoint, bb)
-	if err != nil {
-		return nil, nil, err
+	var resp *http.Response
+	var b []byte
+	for refreshed := false; ; refreshed = true {
+		var token *AccessToken
+		err := c.retry(ctx, idempotent, method+" "+path, func() (int, error) {
+			var err error
+			resp, b, token, err = c.sendRateLimited(ctx, method, endpoint, bb)
+			if err != nil {
+				return 0, err
+			}
+			if resp.StatusCode == http.StatusTooManyRequests {
+				// sendRateLimited already waited out the rate limit as often as it is worth it.
+				return 0, nil
+			}
+			return resp.StatusCode, nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// A 401 with a token we thought was good means it was revoked, try once more with a new one.
+		if resp.StatusCode != http.StatusUnauthorized || token == nil || refreshed {
+			break
+		}
+		c.log("auth: request unauthorized, invalidating access token (%s %s)", method, path)
+		c.invalidateToken(token)
 	}
 
-	err = c.authenticate(request)
-	if err != nil {
-		c.log("authentication failed: %s", err)
-		return nil, nil, err
+	if resp.StatusCode >= 400 {
+		return resp, nil, newAPIError(method, path, resp, b)
 	}
+	return resp, b, nil
+}
 
-	request.Header.Set("User-Agent", "libtwitch/v1")
-	request.Header.Set("Content-Type", "application/json")
+// sendRateLimited sends an API request once the rate limit allows it. Requests rejected with a
+// 429 were not processed by twitch, so they are re-sent after the bucket resets.
+func (c *TwitchClient) sendRateLimited(ctx context.Context, method string, endpoint string, body []byte) (*http.Response, []byte, *AccessToken, error) {
+	for waits := 0; ; waits++ {
+		err := c.ratelimit.wait(ctx)
+		if err != nil {
+			return nil, nil, nil, permanentError{err}
+		}
 
-	resp, b, err := c.doRequest(request)
+		resp, b, token, err := c.send(ctx, method, endpoint, body)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c.ratelimit.update(resp.Header)
+
+		if resp.StatusCode != http.StatusTooManyRequests || waits >= maxRateLimitWaits {
+			return resp, b, token, nil
+		}
+		if c.ratelimit.exhaust() {
+			c.log("rate limited: waiting until %s (%s %s)", c.ratelimit.get().Reset, method, endpoint)
+			continue
+		}
+
+		c.log("rate limited: backing off for %s (%s %s)", rateLimitBackoff, method, endpoint)
+		t := time.NewTimer(rateLimitBackoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, nil, nil, permanentError{ctx.Err()}
+		}
+	}
+}
+
+// send makes a single authenticated attempt at an API request. The access token used, if any, is
+// returned along with the response.
+func (c *TwitchClient) send(ctx context.Context, method string, endpoint string, body []byte) (*http.Response, []byte, *AccessToken, error) {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, permanentError{err}
 	}
 
-	response, err := c.marshalResponse(b)
+	token, err := c.authenticate(ctx, request)
 	if err != nil {
-		return nil, nil, err
+		c.log("authentication failed: %s", err)
+		return nil, nil, nil, permanentError{err}
 	}
-	return resp, response.Data, nil
+
+	request.Header.Set("User-Agent", c.userAgent)
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, b, err := c.doRequest(request)
+	return resp, b, token, err
 }
@@ -0,0 +1,48 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a client talking to a test server serving h, for both the API and OAuth
+// endpoints (the latter under /oauth2/).
+func newTestClient(t *testing.T, h http.Handler, opts ...Option) *TwitchClient {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{
+		WithAPIEndpoint(srv.URL),
+		WithOAuthEndpoint(srv.URL + "/oauth2/"),
+	}, opts...)
+	c, err := NewTwitchClient(context.Background(), "client-id", opts...)
+	if err != nil {
+		t.Fatalf("NewTwitchClient: %s", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestRequestPageAPIError(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"Not Found","status":404,"message":"no such thing"}`)
+	}), WithRetryPolicy(NoRetryPolicy))
+
+	_, _, err := c.RequestPage(context.Background(), "GET", "things", nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %v", err)
+	}
+	if apiErr.Message != "no such thing" || apiErr.Path != "things" {
+		t.Errorf("unexpected error fields: %+v", apiErr)
+	}
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrServerError) {
+		t.Errorf("error matches the wrong sentinels: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package libtwitch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Clip struct {
+	ID              string    `json:"id"`
+	URL             string    `json:"url"`
+	EmbedURL        string    `json:"embed_url"`
+	BroadcasterID   string    `json:"broadcaster_id"`
+	BroadcasterName string    `json:"broadcaster_name"`
+	CreatorID       string    `json:"creator_id"`
+	CreatorName     string    `json:"creator_name"`
+	VideoID         string    `json:"video_id"`
+	GameID          string    `json:"game_id"`
+	Language        string    `json:"language"`
+	Title           string    `json:"title"`
+	ViewCount       int       `json:"view_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	ThumbnailURL    string    `json:"thumbnail_url"`
+	Duration        float64   `json:"duration"`   // Seconds
+	VODOffset       *int      `json:"vod_offset"` // Seconds into the video, nil if unavailable
+	IsFeatured      bool      `json:"is_featured"`
+}
+
+// CreatedClip is a clip that is still being processed, see CreateClip.
+type CreatedClip struct {
+	ID      string `json:"id"`
+	EditURL string `json:"edit_url"`
+}
+
+// ClipsQuery selects the clips GetClips returns. Exactly one of BroadcasterID, GameID or IDs must
+// be set, the time window is optional.
+type ClipsQuery struct {
+	BroadcasterID string
+	GameID        string
+	IDs           []string
+	StartedAt     time.Time
+	EndedAt       time.Time
+	IsFeatured    *bool
+	First         int    // Page size, up to 100
+	After         string // Cursor to resume from, see Paginator.Cursor
+}
+
+func (q ClipsQuery) values() url.Values {
+	v := url.Values{}
+	if q.BroadcasterID != "" {
+		v.Set("broadcaster_id", q.BroadcasterID)
+	}
+	if q.GameID != "" {
+		v.Set("game_id", q.GameID)
+	}
+	if len(q.IDs) > 0 {
+		v["id"] = q.IDs
+	}
+	if !q.StartedAt.IsZero() {
+		v.Set("started_at", q.StartedAt.UTC().Format(time.RFC3339))
+	}
+	if !q.EndedAt.IsZero() {
+		v.Set("ended_at", q.EndedAt.UTC().Format(time.RFC3339))
+	}
+	if q.IsFeatured != nil {
+		v.Set("is_featured", strconv.FormatBool(*q.IsFeatured))
+	}
+	if q.First > 0 {
+		v.Set("first", strconv.Itoa(q.First))
+	}
+	return v
+}
+
+// CreateClip clips the last seconds of a live stream. Twitch processes the clip asynchronously,
+// use GetClips with the returned id to check when it is available. It requires a user token
+// with the clips:edit scope.
+func (c *TwitchClient) CreateClip(ctx context.Context, broadcasterID string, hasDelay bool) (*CreatedClip, error) {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"has_delay":      []string{strconv.FormatBool(hasDelay)},
+	}
+	resp, body, err := c.RequestContext(ctx, "POST", "clips", params, nil)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
+	}
+
+	clips, err := decodeData[*CreatedClip](body)
+	if err != nil {
+		return nil, err
+	}
+	if len(clips) == 0 {
+		return nil, NewTwitchClientError("no clip created", nil)
+	}
+	return clips[0], nil
+}
+
+// GetClips lists clips matching the query.
+func (c *TwitchClient) GetClips(ctx context.Context, q ClipsQuery) *Paginator[*Clip] {
+	p := newPaginator[*Clip](c, "clips", q.values()).bind(ctx)
+	p.cursor = q.After
+	return p
+}
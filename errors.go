@@ -0,0 +1,136 @@
+package libtwitch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type TwitchClientError struct {
+	OriginalError error
+	message       string
+}
+
+func (e *TwitchClientError) Error() string {
+	if e.OriginalError != nil {
+		return fmt.Sprintf("%s: %s", e.message, e.OriginalError)
+	}
+	return e.message
+}
+
+// Unwrap returns the original error, so errors.Is/errors.As see through TwitchClientError.
+func (e *TwitchClientError) Unwrap() error {
+	return e.OriginalError
+}
+
+func NewTwitchClientError(message string, originalError error) *TwitchClientError {
+	return &TwitchClientError{
+		message:       message,
+		OriginalError: originalError,
+	}
+}
+
+var ErrMultipleResults = NewTwitchClientError("multiple results found", nil)
+var ErrNotFound = NewTwitchClientError("not found", nil)
+
+// Sentinels matching APIErrors by status code, e.g. errors.Is(err, ErrRateLimited).
+var (
+	ErrBadRequest   = NewTwitchClientError("bad request", nil)
+	ErrUnauthorized = NewTwitchClientError("unauthorized", nil)
+	ErrForbidden    = NewTwitchClientError("forbidden", nil)
+	ErrConflict     = NewTwitchClientError("conflict", nil)
+	ErrRateLimited  = NewTwitchClientError("rate limited", nil)
+	ErrServerError  = NewTwitchClientError("server error", nil)
+)
+
+// Sentinels matching APIErrors of moderation requests that failed because the user already is in
+// the requested state, e.g. errors.Is(err, ErrAlreadyBanned).
+var (
+	ErrAlreadyBanned = NewTwitchClientError("user is already banned", nil)
+	ErrNotBanned     = NewTwitchClientError("user is not banned", nil)
+)
+
+// ErrMissingScope is returned before making a request the client's token lacks the required
+// OAuth scopes for. Any one of the Required scopes would do.
+type ErrMissingScope struct {
+	Method   string
+	Path     string
+	Required []Scope
+}
+
+func (e *ErrMissingScope) Error() string {
+	if len(e.Required) == 1 {
+		return fmt.Sprintf("twitch: %s %s requires a user token with scope: %s", e.Method, e.Path, e.Required[0])
+	}
+	return fmt.Sprintf("twitch: %s %s requires a user token with one of the scopes: %s", e.Method, e.Path, joinScopes(e.Required))
+}
+
+// APIError is a non-2xx response from twitch, e.g.
+//
+//	{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}
+type APIError struct {
+	StatusCode int    `json:"status"`
+	ErrorName  string `json:"error"`
+	Message    string `json:"message"`
+
+	Method    string      `json:"-"`
+	Path      string      `json:"-"`
+	RateLimit RateLimit   `json:"-"`
+	Header    http.Header `json:"-"`
+}
+
+// newAPIError builds an APIError from a response and its raw body. Bodies that aren't helix
+// errors just leave the twitch fields empty.
+func newAPIError(method, path string, resp *http.Response, body []byte) *APIError {
+	e := &APIError{}
+	_ = json.Unmarshal(body, e)
+
+	e.StatusCode = resp.StatusCode
+	if e.ErrorName == "" {
+		e.ErrorName = http.StatusText(resp.StatusCode)
+	}
+	e.Method = method
+	e.Path = path
+	e.RateLimit, _ = parseRateLimit(resp.Header)
+	e.Header = resp.Header
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("twitch: %s %s: %d %s", e.Method, e.Path, e.StatusCode, e.ErrorName)
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	return msg
+}
+
+// Is matches the status code sentinels (ErrNotFound, ErrUnauthorized, ...) and the moderation
+// sentinels (ErrAlreadyBanned, ErrNotBanned), which twitch only tells apart by message.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500
+	case ErrAlreadyBanned:
+		return e.StatusCode == http.StatusBadRequest && e.messageContains("is already banned")
+	case ErrNotBanned:
+		return e.StatusCode == http.StatusBadRequest && e.messageContains("is not banned")
+	}
+	return false
+}
+
+func (e *APIError) messageContains(s string) bool {
+	return strings.Contains(strings.ToLower(e.Message), s)
+}
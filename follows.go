@@ -0,0 +1,71 @@
+package libtwitch
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ChannelFollower is a user following a channel, see GetChannelFollowers.
+type ChannelFollower struct {
+	UserID     string    `json:"user_id"`
+	UserLogin  string    `json:"user_login"`
+	UserName   string    `json:"user_name"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowedChannel is a channel a user follows, see GetFollowedChannels.
+type FollowedChannel struct {
+	BroadcasterID    string    `json:"broadcaster_id"`
+	BroadcasterLogin string    `json:"broadcaster_login"`
+	BroadcasterName  string    `json:"broadcaster_name"`
+	FollowedAt       time.Time `json:"followed_at"`
+}
+
+// GetChannelFollowers lists a channel's followers, newest first. The paginator's Total is the
+// follower count. Listing followers requires a user token of the broadcaster (or a moderator)
+// with the moderator:read:followers scope, otherwise twitch only reports the total, see
+// GetChannelFollowerCount.
+func (c *TwitchClient) GetChannelFollowers(ctx context.Context, broadcasterID string) *Paginator[*ChannelFollower] {
+	return newPaginator[*ChannelFollower](c, "channels/followers", url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"first":          []string{strconv.Itoa(maxPageSize)},
+	}).bind(ctx)
+}
+
+// GetChannelFollowerCount returns the number of users following a channel.
+func (c *TwitchClient) GetChannelFollowerCount(ctx context.Context, broadcasterID string) (int, error) {
+	p := newPaginator[*ChannelFollower](c, "channels/followers", url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"first":          []string{"1"},
+	})
+	_, err := p.Next(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return p.Total(), nil
+}
+
+// GetFollowedChannels lists the channels a user follows, newest first. The paginator's Total is
+// the number of followed channels. It requires a user token of that user with the
+// user:read:follows scope.
+func (c *TwitchClient) GetFollowedChannels(ctx context.Context, userID string) *Paginator[*FollowedChannel] {
+	return newPaginator[*FollowedChannel](c, "channels/followed", url.Values{
+		"user_id": []string{userID},
+		"first":   []string{strconv.Itoa(maxPageSize)},
+	}).bind(ctx)
+}
+
+// IsFollowing reports whether a user follows a channel. Like GetFollowedChannels, it requires a
+// user token of that user with the user:read:follows scope.
+func (c *TwitchClient) IsFollowing(ctx context.Context, userID, broadcasterID string) (bool, error) {
+	follows, err := getMany[*FollowedChannel](ctx, c, "channels/followed", url.Values{
+		"user_id":        []string{userID},
+		"broadcaster_id": []string{broadcasterID},
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(follows) > 0, nil
+}
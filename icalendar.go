@@ -0,0 +1,75 @@
+package libtwitch
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalTimeFormat = "20060102T150405Z"
+
+	// icalLineLength is the most octets RFC 5545 allows per content line, excluding the CRLF.
+	icalLineLength = 75
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// ICalendar renders schedule segments, possibly of several broadcasters, as an iCalendar
+// (RFC 5545) document. Canceled segments are included with a CANCELLED status.
+func ICalendar(segments []*ScheduleSegment) []byte {
+	now := time.Now().UTC().Format(icalTimeFormat)
+
+	buf := bytes.NewBuffer(nil)
+	writeICalLine(buf, "BEGIN:VCALENDAR")
+	writeICalLine(buf, "VERSION:2.0")
+	writeICalLine(buf, "PRODID:-//libtwitch//schedule//EN")
+	writeICalLine(buf, "CALSCALE:GREGORIAN")
+	for _, s := range segments {
+		summary := s.Title
+		if s.BroadcasterName != "" {
+			summary = s.BroadcasterName + ": " + summary
+		}
+
+		writeICalLine(buf, "BEGIN:VEVENT")
+		writeICalLine(buf, "UID:"+icalEscaper.Replace(s.ID)+"@twitch.tv")
+		writeICalLine(buf, "DTSTAMP:"+now)
+		writeICalLine(buf, "DTSTART:"+s.StartTime.UTC().Format(icalTimeFormat))
+		if !s.EndTime.IsZero() {
+			writeICalLine(buf, "DTEND:"+s.EndTime.UTC().Format(icalTimeFormat))
+		}
+		writeICalLine(buf, "SUMMARY:"+icalEscaper.Replace(summary))
+		if s.Category != nil {
+			writeICalLine(buf, "CATEGORIES:"+icalEscaper.Replace(s.Category.Name))
+		}
+		if s.BroadcasterLogin != "" {
+			writeICalLine(buf, "URL:https://www.twitch.tv/"+s.BroadcasterLogin)
+		}
+		if s.CanceledUntil != nil {
+			writeICalLine(buf, "STATUS:CANCELLED")
+		}
+		writeICalLine(buf, "END:VEVENT")
+	}
+	writeICalLine(buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeICalLine writes a content line, folding it into continuation lines (starting with a space)
+// without splitting UTF-8 sequences.
+func writeICalLine(buf *bytes.Buffer, line string) {
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of continuation lines counts towards their length.
+		limit = icalLineLength - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,69 @@
+package libtwitch
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestICalendar(t *testing.T) {
+	canceled := time.Date(2021, 7, 8, 0, 0, 0, 0, time.UTC)
+	segments := []*ScheduleSegment{
+		{
+			ID:               "seg1",
+			StartTime:        time.Date(2021, 7, 1, 18, 0, 0, 0, time.UTC),
+			EndTime:          time.Date(2021, 7, 1, 20, 0, 0, 0, time.UTC),
+			Title:            "Speedruns, practice; and\nchat",
+			Category:         &ScheduleCategory{ID: "1", Name: "Chess"},
+			BroadcasterName:  "Bob",
+			BroadcasterLogin: "bob",
+		},
+		{
+			ID:            "seg2",
+			StartTime:     time.Date(2021, 7, 2, 18, 0, 0, 0, time.UTC),
+			Title:         strings.Repeat("é", 100),
+			CanceledUntil: &canceled,
+		},
+	}
+	ics := string(ICalendar(segments))
+
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+		t.Errorf("expected a CRLF terminated VCALENDAR, got %q", ics)
+	}
+	for _, want := range []string{
+		"UID:seg1@twitch.tv\r\n",
+		"DTSTART:20210701T180000Z\r\n",
+		"DTEND:20210701T200000Z\r\n",
+		`SUMMARY:Bob: Speedruns\, practice\; and\nchat` + "\r\n",
+		"CATEGORIES:Chess\r\n",
+		"URL:https://www.twitch.tv/bob\r\n",
+		"STATUS:CANCELLED\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("expected %q in %q", want, ics)
+		}
+	}
+	if strings.Count(ics, "DTEND:") != 1 {
+		t.Error("expected DTEND to be omitted for segments without an end time")
+	}
+
+	// Long lines are folded at 75 octets without splitting UTF-8 sequences.
+	unfolded := ""
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > icalLineLength {
+			t.Errorf("line longer than %d octets: %q", icalLineLength, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 sequence: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded += line[1:]
+		} else {
+			unfolded += "\n" + line
+		}
+	}
+	if !strings.Contains(unfolded, "\nSUMMARY:"+strings.Repeat("é", 100)+"\n") {
+		t.Errorf("expected the folded summary to unfold to the original, got %q", unfolded)
+	}
+}
@@ -0,0 +1,341 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// The moderation endpoints act on behalf of the token's user: moderatorID must be the id of the
+// user the client's token belongs to, and that user must be the broadcaster or one of their
+// moderators.
+
+// Ban is the result of BanUser and TimeoutUser. EndTime is nil for permanent bans.
+type Ban struct {
+	BroadcasterID string     `json:"broadcaster_id"`
+	ModeratorID   string     `json:"moderator_id"`
+	UserID        string     `json:"user_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	EndTime       *time.Time `json:"end_time"`
+}
+
+// BannedUser is a user banned from a channel. ExpiresAt is zero for permanent bans.
+type BannedUser struct {
+	UserID         string    `json:"user_id"`
+	UserLogin      string    `json:"user_login"`
+	UserName       string    `json:"user_name"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	Reason         string    `json:"reason"`
+	ModeratorID    string    `json:"moderator_id"`
+	ModeratorLogin string    `json:"moderator_login"`
+	ModeratorName  string    `json:"moderator_name"`
+}
+
+func (u *BannedUser) UnmarshalJSON(b []byte) error {
+	type alias BannedUser
+	aux := struct {
+		*alias
+		ExpiresAt string `json:"expires_at"`
+	}{alias: (*alias)(u)}
+
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+	u.ExpiresAt, err = parseOptionalTime(aux.ExpiresAt)
+	return err
+}
+
+// ChannelUser is a user holding a role in a channel, i.e. a moderator or VIP.
+type ChannelUser struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+}
+
+// BlockedTerm is a word or phrase blocked in a channel's chat. ExpiresAt is nil for terms that
+// don't expire.
+type BlockedTerm struct {
+	ID            string     `json:"id"`
+	BroadcasterID string     `json:"broadcaster_id"`
+	ModeratorID   string     `json:"moderator_id"`
+	Text          string     `json:"text"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+}
+
+// AutoModSettings are a channel's AutoMod levels, from 0 (no filtering) to 4 (most filtering).
+// OverallLevel is nil if the levels were set individually; when updating, a non-nil OverallLevel
+// overrides the individual levels.
+type AutoModSettings struct {
+	BroadcasterID           string `json:"broadcaster_id"`
+	ModeratorID             string `json:"moderator_id"`
+	OverallLevel            *int   `json:"overall_level"`
+	Disability              int    `json:"disability"`
+	Aggression              int    `json:"aggression"`
+	SexualitySexOrGender    int    `json:"sexuality_sex_or_gender"`
+	Misogyny                int    `json:"misogyny"`
+	Bullying                int    `json:"bullying"`
+	Swearing                int    `json:"swearing"`
+	RaceEthnicityOrReligion int    `json:"race_ethnicity_or_religion"`
+	SexBasedTerms           int    `json:"sex_based_terms"`
+}
+
+// requestNoContent makes a request that is answered with 204 No Content on success.
+func (c *TwitchClient) requestNoContent(ctx context.Context, method, path string, params *url.Values, body interface{}) error {
+	resp, _, err := c.RequestContext(ctx, method, path, params, body)
+	if err != nil {
+		return NewTwitchClientError("error making request", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		return NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// BanUser permanently bans a user from the broadcaster's chat. It fails with ErrAlreadyBanned if
+// the user is banned already. It requires the moderator:manage:banned_users scope.
+func (c *TwitchClient) BanUser(ctx context.Context, broadcasterID, moderatorID, userID, reason string) (*Ban, error) {
+	return c.ban(ctx, broadcasterID, moderatorID, userID, 0, reason)
+}
+
+// TimeoutUser bans a user from the broadcaster's chat for duration (rounded to seconds, up to two
+// weeks), replacing any existing timeout. It fails with ErrAlreadyBanned if the user is banned
+// permanently. It requires the moderator:manage:banned_users scope.
+func (c *TwitchClient) TimeoutUser(ctx context.Context, broadcasterID, moderatorID, userID string, duration time.Duration, reason string) (*Ban, error) {
+	seconds := int(duration.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return c.ban(ctx, broadcasterID, moderatorID, userID, seconds, reason)
+}
+
+func (c *TwitchClient) ban(ctx context.Context, broadcasterID, moderatorID, userID string, seconds int, reason string) (*Ban, error) {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+	}
+	data := map[string]interface{}{"user_id": userID}
+	if seconds > 0 {
+		data["duration"] = seconds
+	}
+	if reason != "" {
+		data["reason"] = reason
+	}
+
+	_, body, err := c.RequestContext(ctx, "POST", "moderation/bans", params, map[string]interface{}{"data": data})
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+
+	bans, err := decodeData[*Ban](body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bans) == 0 {
+		return nil, NewTwitchClientError("no ban returned", nil)
+	}
+	return bans[0], nil
+}
+
+// UnbanUser lifts a ban or timeout. It fails with ErrNotBanned if the user isn't banned. It
+// requires the moderator:manage:banned_users scope.
+func (c *TwitchClient) UnbanUser(ctx context.Context, broadcasterID, moderatorID, userID string) error {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+		"user_id":        []string{userID},
+	}
+	return c.requestNoContent(ctx, "DELETE", "moderation/bans", params, nil)
+}
+
+// GetBannedUsers lists the users banned from the broadcaster's chat, optionally only the given
+// ones. It requires a user token of the broadcaster with the moderation:read or
+// moderator:manage:banned_users scope.
+func (c *TwitchClient) GetBannedUsers(ctx context.Context, broadcasterID string, userIDs ...string) *Paginator[*BannedUser] {
+	return newPaginator[*BannedUser](c, "moderation/banned", channelUserParams(broadcasterID, userIDs)).bind(ctx)
+}
+
+// GetModerators lists the broadcaster's moderators, optionally only the given users. It requires
+// a user token of the broadcaster with the moderation:read or channel:manage:moderators scope.
+func (c *TwitchClient) GetModerators(ctx context.Context, broadcasterID string, userIDs ...string) *Paginator[*ChannelUser] {
+	return newPaginator[*ChannelUser](c, "moderation/moderators", channelUserParams(broadcasterID, userIDs)).bind(ctx)
+}
+
+// AddModerator makes a user a moderator of the broadcaster's channel. It requires a user token of
+// the broadcaster with the channel:manage:moderators scope.
+func (c *TwitchClient) AddModerator(ctx context.Context, broadcasterID, userID string) error {
+	params := channelUserParams(broadcasterID, []string{userID})
+	return c.requestNoContent(ctx, "POST", "moderation/moderators", &params, nil)
+}
+
+// RemoveModerator removes a moderator of the broadcaster's channel. It requires a user token of
+// the broadcaster with the channel:manage:moderators scope.
+func (c *TwitchClient) RemoveModerator(ctx context.Context, broadcasterID, userID string) error {
+	params := channelUserParams(broadcasterID, []string{userID})
+	return c.requestNoContent(ctx, "DELETE", "moderation/moderators", &params, nil)
+}
+
+// GetVIPs lists the broadcaster's VIPs, optionally only the given users. It requires a user token
+// of the broadcaster with the channel:read:vips or channel:manage:vips scope.
+func (c *TwitchClient) GetVIPs(ctx context.Context, broadcasterID string, userIDs ...string) *Paginator[*ChannelUser] {
+	return newPaginator[*ChannelUser](c, "channels/vips", channelUserParams(broadcasterID, userIDs)).bind(ctx)
+}
+
+// AddVIP makes a user a VIP of the broadcaster's channel. It requires a user token of the
+// broadcaster with the channel:manage:vips scope.
+func (c *TwitchClient) AddVIP(ctx context.Context, broadcasterID, userID string) error {
+	params := channelUserParams(broadcasterID, []string{userID})
+	return c.requestNoContent(ctx, "POST", "channels/vips", &params, nil)
+}
+
+// RemoveVIP removes a VIP of the broadcaster's channel. It requires a user token of the
+// broadcaster with the channel:manage:vips scope.
+func (c *TwitchClient) RemoveVIP(ctx context.Context, broadcasterID, userID string) error {
+	params := channelUserParams(broadcasterID, []string{userID})
+	return c.requestNoContent(ctx, "DELETE", "channels/vips", &params, nil)
+}
+
+func channelUserParams(broadcasterID string, userIDs []string) url.Values {
+	v := url.Values{"broadcaster_id": []string{broadcasterID}}
+	if len(userIDs) > 0 {
+		v["user_id"] = userIDs
+	}
+	return v
+}
+
+// GetBlockedTerms lists the terms blocked in the broadcaster's chat. It requires the
+// moderator:read:blocked_terms or moderator:manage:blocked_terms scope.
+func (c *TwitchClient) GetBlockedTerms(ctx context.Context, broadcasterID, moderatorID string) *Paginator[*BlockedTerm] {
+	return newPaginator[*BlockedTerm](c, "moderation/blocked_terms", url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+		"first":          []string{strconv.Itoa(maxPageSize)},
+	}).bind(ctx)
+}
+
+// AddBlockedTerm blocks a word or phrase (2 to 500 characters, * is a wildcard) in the
+// broadcaster's chat. Adding a term that is blocked already returns the existing one. It
+// requires the moderator:manage:blocked_terms scope.
+func (c *TwitchClient) AddBlockedTerm(ctx context.Context, broadcasterID, moderatorID, text string) (*BlockedTerm, error) {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+	}
+	_, body, err := c.RequestContext(ctx, "POST", "moderation/blocked_terms", params, map[string]string{"text": text})
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+
+	terms, err := decodeData[*BlockedTerm](body)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 0 {
+		return nil, NewTwitchClientError("no blocked term returned", nil)
+	}
+	return terms[0], nil
+}
+
+// RemoveBlockedTerm unblocks a term by its id. It requires the moderator:manage:blocked_terms
+// scope.
+func (c *TwitchClient) RemoveBlockedTerm(ctx context.Context, broadcasterID, moderatorID, id string) error {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+		"id":             []string{id},
+	}
+	return c.requestNoContent(ctx, "DELETE", "moderation/blocked_terms", params, nil)
+}
+
+// GetAutoModSettings fetches the broadcaster's AutoMod settings. It requires the
+// moderator:read:automod_settings or moderator:manage:automod_settings scope.
+func (c *TwitchClient) GetAutoModSettings(ctx context.Context, broadcasterID, moderatorID string) (*AutoModSettings, error) {
+	return getOne[*AutoModSettings](ctx, c, "moderation/automod/settings", url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+	})
+}
+
+// UpdateAutoModSettings replaces the broadcaster's AutoMod settings and returns the new ones. It
+// requires the moderator:manage:automod_settings scope.
+func (c *TwitchClient) UpdateAutoModSettings(ctx context.Context, broadcasterID, moderatorID string, settings AutoModSettings) (*AutoModSettings, error) {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+	}
+
+	// Twitch rejects the overall level along with individual ones.
+	var body interface{}
+	if settings.OverallLevel != nil {
+		body = map[string]int{"overall_level": *settings.OverallLevel}
+	} else {
+		body = map[string]int{
+			"disability":                 settings.Disability,
+			"aggression":                 settings.Aggression,
+			"sexuality_sex_or_gender":    settings.SexualitySexOrGender,
+			"misogyny":                   settings.Misogyny,
+			"bullying":                   settings.Bullying,
+			"swearing":                   settings.Swearing,
+			"race_ethnicity_or_religion": settings.RaceEthnicityOrReligion,
+			"sex_based_terms":            settings.SexBasedTerms,
+		}
+	}
+
+	_, data, err := c.RequestContext(ctx, "PUT", "moderation/automod/settings", params, body)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+
+	results, err := decodeData[*AutoModSettings](data)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, NewTwitchClientError("no settings returned", nil)
+	}
+	return results[0], nil
+}
+
+// ManageHeldAutoModMessage allows or denies a chat message AutoMod held for review. It requires
+// the moderator:manage:automod scope.
+func (c *TwitchClient) ManageHeldAutoModMessage(ctx context.Context, moderatorID, messageID string, allow bool) error {
+	action := "DENY"
+	if allow {
+		action = "ALLOW"
+	}
+	body := map[string]string{
+		"user_id": moderatorID,
+		"msg_id":  messageID,
+		"action":  action,
+	}
+	return c.requestNoContent(ctx, "POST", "moderation/automod/message", nil, body)
+}
+
+// DeleteChatMessage deletes a single message from the broadcaster's chat. It requires the
+// moderator:manage:chat_messages scope.
+func (c *TwitchClient) DeleteChatMessage(ctx context.Context, broadcasterID, moderatorID, messageID string) error {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+		"message_id":     []string{messageID},
+	}
+	return c.requestNoContent(ctx, "DELETE", "moderation/chat", params, nil)
+}
+
+// ClearChat deletes all messages from the broadcaster's chat. It requires the
+// moderator:manage:chat_messages scope.
+func (c *TwitchClient) ClearChat(ctx context.Context, broadcasterID, moderatorID string) error {
+	params := &url.Values{
+		"broadcaster_id": []string{broadcasterID},
+		"moderator_id":   []string{moderatorID},
+	}
+	return c.requestNoContent(ctx, "DELETE", "moderation/chat", params, nil)
+}
@@ -0,0 +1,88 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// moderationClient returns a client with a moderator token whose requests are all answered with
+// status and body.
+func moderationClient(t *testing.T, status int, body string) *TwitchClient {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}), WithUserToken(&AccessToken{AccessToken: "token", Scopes: NewScopeSet(ScopeModeratorManageBannedUsers)}))
+}
+
+func TestBanUserAlreadyBanned(t *testing.T) {
+	c := moderationClient(t, http.StatusBadRequest,
+		`{"error":"Bad Request","status":400,"message":"The user specified in the user_id field is already banned."}`)
+
+	_, err := c.BanUser(context.Background(), "1", "2", "3", "spam")
+	var clientErr *TwitchClientError
+	if !errors.As(err, &clientErr) {
+		t.Fatalf("expected a *TwitchClientError, got %v", err)
+	}
+	if !errors.Is(err, ErrAlreadyBanned) || !errors.Is(err, ErrBadRequest) {
+		t.Errorf("expected ErrAlreadyBanned and ErrBadRequest, got %v", err)
+	}
+	if errors.Is(err, ErrNotBanned) {
+		t.Errorf("expected no ErrNotBanned, got %v", err)
+	}
+
+	_, err = c.TimeoutUser(context.Background(), "1", "2", "3", time.Minute, "")
+	if !errors.Is(err, ErrAlreadyBanned) {
+		t.Errorf("expected ErrAlreadyBanned for timeouts, got %v", err)
+	}
+}
+
+func TestBanUserOtherBadRequest(t *testing.T) {
+	c := moderationClient(t, http.StatusBadRequest,
+		`{"error":"Bad Request","status":400,"message":"The value in the duration field is not valid."}`)
+
+	_, err := c.BanUser(context.Background(), "1", "2", "3", "")
+	if !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest, got %v", err)
+	}
+	if errors.Is(err, ErrAlreadyBanned) || errors.Is(err, ErrNotBanned) {
+		t.Errorf("expected a plain bad request, got %v", err)
+	}
+}
+
+func TestUnbanUserNotBanned(t *testing.T) {
+	c := moderationClient(t, http.StatusBadRequest,
+		`{"error":"Bad Request","status":400,"message":"The user specified in the user_id field is not banned."}`)
+
+	err := c.UnbanUser(context.Background(), "1", "2", "3")
+	if !errors.Is(err, ErrNotBanned) {
+		t.Errorf("expected ErrNotBanned, got %v", err)
+	}
+	if errors.Is(err, ErrAlreadyBanned) {
+		t.Errorf("expected no ErrAlreadyBanned, got %v", err)
+	}
+}
+
+func TestUnbanUserOtherBadRequest(t *testing.T) {
+	c := moderationClient(t, http.StatusBadRequest,
+		`{"error":"Bad Request","status":400,"message":"The ID in moderator_id must match the user ID in the access token."}`)
+
+	err := c.UnbanUser(context.Background(), "1", "2", "3")
+	if !errors.Is(err, ErrBadRequest) || errors.Is(err, ErrNotBanned) {
+		t.Errorf("expected a plain bad request, got %v", err)
+	}
+}
+
+func TestUnbanUserOtherStatus(t *testing.T) {
+	// The message alone isn't enough, only 400s are matched.
+	c := moderationClient(t, http.StatusConflict,
+		`{"error":"Conflict","status":409,"message":"The user is not banned, but someone else is updating their ban state."}`)
+
+	err := c.UnbanUser(context.Background(), "1", "2", "3")
+	if !errors.Is(err, ErrConflict) || errors.Is(err, ErrNotBanned) {
+		t.Errorf("expected a plain conflict, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package libtwitch
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AuthorizeURL returns the URL to send a user to so they can grant the given scopes to the
+// application (OAuth authorization code flow). Twitch redirects back to redirectURI with a code
+// and the given state, see AuthorizationHandler and ExchangeCode.
+func (c *TwitchClient) AuthorizeURL(redirectURI string, scopes []Scope, state string) string {
+	v := url.Values{
+		"client_id":     []string{c.clientID},
+		"redirect_uri":  []string{redirectURI},
+		"response_type": []string{"code"},
+		"scope":         []string{joinScopes(scopes)},
+		"state":         []string{state},
+	}
+	return c.oauthEndpoint + "authorize?" + v.Encode()
+}
+
+// ExchangeCode exchanges an authorization code for a user access token. redirectURI must match
+// the one passed to AuthorizeURL.
+func (c *TwitchClient) ExchangeCode(ctx context.Context, code, redirectURI string) (*AccessToken, error) {
+	if c.clientSecret == "" {
+		return nil, NewTwitchClientError("client secret is required to exchange authorization codes", nil)
+	}
+
+	return c.requestToken(ctx, url.Values{
+		"client_id":     []string{c.clientID},
+		"client_secret": []string{c.clientSecret},
+		"code":          []string{code},
+		"grant_type":    []string{"authorization_code"},
+		"redirect_uri":  []string{redirectURI},
+	})
+}
+
+// RefreshUserToken gets a new user access token with the given refresh token. Twitch may rotate
+// the refresh token, the old one is kept if the response doesn't include a new one.
+func (c *TwitchClient) RefreshUserToken(ctx context.Context, refreshToken string) (*AccessToken, error) {
+	v := url.Values{
+		"client_id":     []string{c.clientID},
+		"grant_type":    []string{"refresh_token"},
+		"refresh_token": []string{refreshToken},
+	}
+	if c.clientSecret != "" {
+		v.Set("client_secret", c.clientSecret)
+	}
+
+	t, err := c.requestToken(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken
+	}
+	return t, nil
+}
+
+// AuthorizationHandler handles the redirect back from twitch after a user visited AuthorizeURL.
+// It checks the state, exchanges the code for a user access token and hands the result to
+// callback. Use SetUserToken to make requests with the token.
+func (c *TwitchClient) AuthorizationHandler(redirectURI, state string, callback func(*AccessToken, error)) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		if subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(state)) != 1 {
+			c.log("oauth: ignoring redirect with invalid state")
+			http.Error(rw, "invalid state", http.StatusBadRequest)
+			return
+		}
+
+		if e := q.Get("error"); e != "" {
+			err := NewTwitchClientError(fmt.Sprintf("authorization denied: %s: %s", e, q.Get("error_description")), nil)
+			callback(nil, err)
+			http.Error(rw, "authorization denied", http.StatusForbidden)
+			return
+		}
+
+		t, err := c.ExchangeCode(r.Context(), q.Get("code"), redirectURI)
+		callback(t, err)
+		if err != nil {
+			c.log("oauth: failed to exchange code: %s", err)
+			http.Error(rw, "authorization failed", http.StatusBadGateway)
+			return
+		}
+
+		rw.Write([]byte("Authorization complete, you can close this window."))
+	})
+}
+
+// DeviceCode is a pending OAuth device authorization, see StartDeviceAuthorization.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+
+	Scopes    []Scope   `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+var ErrDeviceCodeExpired = NewTwitchClientError("device code expired", nil)
+
+// StartDeviceAuthorization starts the OAuth device authorization grant for headless logins. The
+// user has to visit VerificationURI and enter UserCode, meanwhile PollDeviceToken waits for them
+// to do so.
+func (c *TwitchClient) StartDeviceAuthorization(ctx context.Context, scopes []Scope) (*DeviceCode, error) {
+	_, body, err := c.oauthRequest(ctx, "POST", "device", url.Values{
+		"client_id": []string{c.clientID},
+		"scopes":    []string{joinScopes(scopes)},
+	}, "")
+	if err != nil {
+		return nil, NewTwitchClientError("device authorization failed", err)
+	}
+
+	dc := &DeviceCode{}
+	err = json.Unmarshal(body, dc)
+	if err != nil {
+		return nil, NewTwitchClientError("failed to parse response", err)
+	}
+	dc.Scopes = scopes
+	dc.ExpiresAt = time.Now().Add(time.Second * time.Duration(dc.ExpiresIn))
+	return dc, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user completed the device authorization,
+// returning their access token. It gives up with ErrDeviceCodeExpired once the code expires.
+func (c *TwitchClient) PollDeviceToken(ctx context.Context, dc *DeviceCode) (*AccessToken, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	v := url.Values{
+		"client_id":   []string{c.clientID},
+		"device_code": []string{dc.DeviceCode},
+		"grant_type":  []string{"urn:ietf:params:oauth:grant-type:device_code"},
+		"scopes":      []string{joinScopes(dc.Scopes)},
+	}
+
+	for {
+		t := time.NewTimer(interval)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+
+		if time.Now().After(dc.ExpiresAt) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		token, err := c.requestToken(ctx, v)
+		if err == nil {
+			return token, nil
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return nil, err
+		}
+		switch apiErr.Message {
+		case "authorization_pending":
+			c.log("oauth: waiting for device authorization")
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, err
+		}
+	}
+}
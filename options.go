@@ -0,0 +1,107 @@
+package libtwitch
+
+import (
+	logger "log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a TwitchClient, see NewTwitchClient.
+type Option func(*TwitchClient)
+
+// WithClientSecret sets the OAuth client secret used to fetch app access tokens.
+func WithClientSecret(secret string) Option {
+	return func(c *TwitchClient) {
+		c.clientSecret = secret
+	}
+}
+
+// WithUserToken makes the client act on behalf of a user, see SetUserToken.
+func WithUserToken(t *AccessToken) Option {
+	return func(c *TwitchClient) {
+		c.token = t
+		c.userMode = true
+	}
+}
+
+// WithHTTPClient replaces the default http.Client (10s timeout). Useful for sharing
+// connection pools between clients.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *TwitchClient) {
+		c.client = client
+	}
+}
+
+// WithTransport sets the RoundTripper used by the client's http.Client.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *TwitchClient) {
+		client := *c.client
+		client.Transport = transport
+		c.client = &client
+	}
+}
+
+// WithAPIEndpoint overrides the helix base URL, e.g. to point the client at a local stand-in server.
+func WithAPIEndpoint(endpoint string) Option {
+	return func(c *TwitchClient) {
+		c.endpoint = withTrailingSlash(endpoint)
+	}
+}
+
+// WithOAuthEndpoint overrides the OAuth2 base URL (https://id.twitch.tv/oauth2/).
+func WithOAuthEndpoint(endpoint string) Option {
+	return func(c *TwitchClient) {
+		c.oauthEndpoint = withTrailingSlash(endpoint)
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with API requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *TwitchClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLogger sets the logger used for debug output.
+func WithLogger(l *logger.Logger) Option {
+	return func(c *TwitchClient) {
+		c.logger = l
+	}
+}
+
+// WithDebug logs requests, responses and other debugging info.
+func WithDebug(debug bool) Option {
+	return func(c *TwitchClient) {
+		c.debug = debug
+	}
+}
+
+// WithCallbackURL sets the public URL twitch delivers webhook events to, see WebhookHandler.
+func WithCallbackURL(callbackURL string) Option {
+	return func(c *TwitchClient) {
+		c.callbackURL = callbackURL
+	}
+}
+
+// WithCallbackSecret sets the webhook secret. By default a random secret is generated, set a
+// stable one to keep existing subscriptions valid across restarts.
+func WithCallbackSecret(secret string) Option {
+	return func(c *TwitchClient) {
+		c.callbackSecret = secret
+	}
+}
+
+// WithLease sets the webhook subscription lease duration (default 10m).
+func WithLease(lease time.Duration) Option {
+	return func(c *TwitchClient) {
+		c.lease = lease
+	}
+}
+
+func withTrailingSlash(s string) string {
+	if !strings.HasSuffix(s, "/") {
+		return s + "/"
+	}
+	return s
+}
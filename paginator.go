@@ -0,0 +1,178 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// maxPageSize is the largest "first" most helix list endpoints accept.
+const maxPageSize = 100
+
+// Paginator walks the pages of a helix list endpoint using the pagination cursor. Creating one
+// doesn't make any requests. Pages are fetched with the context passed to Next, All or Seq, and
+// are also canceled when the context the paginator was created with is done. It is not safe for
+// concurrent use.
+type Paginator[T any] struct {
+	client *TwitchClient
+	ctx    context.Context // Context the paginator was created with, if any
+	path   string
+	params url.Values
+	decode func(data []byte) ([]T, error)
+
+	// maxFirst is the largest page size the endpoint accepts.
+	maxFirst int
+
+	cursor string
+	total  int
+	done   bool
+}
+
+func newPaginator[T any](c *TwitchClient, path string, params url.Values) *Paginator[T] {
+	if params == nil {
+		params = url.Values{}
+	}
+	return &Paginator[T]{
+		client:   c,
+		path:     path,
+		params:   params,
+		decode:   decodeData[T],
+		maxFirst: maxPageSize,
+	}
+}
+
+// Next fetches the next page. It returns an empty page once the paginator is done.
+func (p *Paginator[T]) Next(ctx context.Context) ([]T, error) {
+	return p.next(ctx, 0)
+}
+
+// bind makes the paginator's requests also end when ctx is done, for constructors taking a context.
+func (p *Paginator[T]) bind(ctx context.Context) *Paginator[T] {
+	p.ctx = ctx
+	return p
+}
+
+// next fetches the next page, of at most first results if first > 0.
+func (p *Paginator[T]) next(ctx context.Context, first int) ([]T, error) {
+	if p.done {
+		return nil, nil
+	}
+
+	if p.ctx != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(p.ctx, cancel)
+		defer stop()
+	}
+
+	params := url.Values{}
+	for k, v := range p.params {
+		params[k] = v
+	}
+	if first > 0 {
+		params.Set("first", strconv.Itoa(first))
+	}
+	if p.cursor != "" {
+		params.Set("after", p.cursor)
+	}
+
+	_, response, err := p.client.RequestPage(ctx, "GET", p.path, &params, nil)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+
+	page, err := p.decode(response.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.total = response.Total
+	p.cursor = response.Pagination.Cursor
+	if p.cursor == "" || len(page) == 0 {
+		p.done = true
+	}
+	return page, nil
+}
+
+// Done reports whether all pages have been fetched.
+func (p *Paginator[T]) Done() bool {
+	return p.done
+}
+
+// Cursor returns the cursor of the next page, which can be used to resume later with the "after"
+// parameter.
+func (p *Paginator[T]) Cursor() string {
+	return p.cursor
+}
+
+// Total returns the total number of results reported by endpoints that include one (0 otherwise).
+// It is only known once the first page was fetched.
+func (p *Paginator[T]) Total() int {
+	return p.total
+}
+
+// pageSize returns the page size requested by the "first" parameter, or the largest one the
+// endpoint accepts.
+func (p *Paginator[T]) pageSize() int {
+	first, err := strconv.Atoi(p.params.Get("first"))
+	if err != nil || first <= 0 || first > p.maxFirst {
+		return p.maxFirst
+	}
+	return first
+}
+
+// All fetches the remaining pages and returns up to limit results, or all of them if limit <= 0.
+// With a limit, the last page is shrunk to fit so Cursor resumes right after the last result.
+func (p *Paginator[T]) All(ctx context.Context, limit int) ([]T, error) {
+	var all []T
+	for !p.done && (limit <= 0 || len(all) < limit) {
+		first := 0
+		if limit > 0 {
+			first = min(limit-len(all), p.pageSize())
+		}
+		page, err := p.next(ctx, first)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, page...)
+	}
+	if limit > 0 && len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+// Seq returns an iterator over the remaining results, fetching pages as needed. It stops after
+// yielding the first error.
+func (p *Paginator[T]) Seq(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for !p.done {
+			page, err := p.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, v := range page {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// decodeData parses the "data" field of a response.
+func decodeData[T any](data []byte) ([]T, error) {
+	results := []T{}
+	if len(data) > 0 {
+		err := json.Unmarshal(data, &results)
+		if err != nil {
+			return nil, NewTwitchClientError("failed to parse response", err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,124 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// streamsHandler serves n streams with ids "0".."n-1", paginated with the offset as cursor and 20
+// results per page by default.
+func streamsHandler(n int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("after"))
+		first, err := strconv.Atoi(q.Get("first"))
+		if err != nil {
+			first = 20
+		}
+
+		streams := []string{}
+		for i := offset; i < n && i < offset+first; i++ {
+			streams = append(streams, fmt.Sprintf(`{"id":"%d","type":"live"}`, i))
+		}
+		cursor := ""
+		if offset+first < n {
+			cursor = strconv.Itoa(offset + first)
+		}
+		fmt.Fprintf(w, `{"data":[%s],"pagination":{"cursor":%q}}`, strings.Join(streams, ","), cursor)
+	}
+}
+
+func streamIDs(streams []*Stream) string {
+	ids := make([]string, len(streams))
+	for i, s := range streams {
+		ids[i] = s.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestPaginatorNext(t *testing.T) {
+	c := newTestClient(t, streamsHandler(5))
+	p := c.GetStreams(context.Background(), StreamsQuery{First: 2})
+
+	var pages []string
+	for !p.Done() {
+		page, err := p.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, streamIDs(page))
+	}
+	if got := strings.Join(pages, "|"); got != "0,1|2,3|4" {
+		t.Errorf("unexpected pages: %s", got)
+	}
+	if page, err := p.Next(context.Background()); page != nil || err != nil {
+		t.Errorf("expected nothing once done, got %v %v", page, err)
+	}
+}
+
+func TestPaginatorAllResumesAtLimit(t *testing.T) {
+	c := newTestClient(t, streamsHandler(50))
+	ctx := context.Background()
+
+	p := c.GetStreams(context.Background(), StreamsQuery{First: 20})
+	first, err := p.All(ctx, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 25 {
+		t.Fatalf("expected 25 streams, got %d", len(first))
+	}
+
+	rest, err := c.GetStreams(context.Background(), StreamsQuery{First: 20, After: p.Cursor()}).All(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := append(first, rest...)
+	for i, s := range all {
+		if s.ID != strconv.Itoa(i) {
+			t.Fatalf("expected stream %d after resuming, got %s (%s)", i, s.ID, streamIDs(all))
+		}
+	}
+	if len(all) != 50 {
+		t.Errorf("expected 50 streams, got %d", len(all))
+	}
+}
+
+func TestPaginatorSeq(t *testing.T) {
+	c := newTestClient(t, streamsHandler(45))
+
+	n := 0
+	for s, err := range c.GetStreams(context.Background(), StreamsQuery{}).Seq(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.ID != strconv.Itoa(n) {
+			t.Fatalf("expected stream %d, got %s", n, s.ID)
+		}
+		n++
+	}
+	if n != 45 {
+		t.Errorf("expected 45 streams, got %d", n)
+	}
+}
+
+func TestPaginatorBoundContext(t *testing.T) {
+	c := newTestClient(t, streamsHandler(5))
+
+	bound, cancel := context.WithCancel(context.Background())
+	p := newPaginator[*Stream](c, "streams", url.Values{"first": []string{"2"}}).bind(bound)
+	if _, err := p.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if _, err := p.Next(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the bound context to cancel requests, got %v", err)
+	}
+}
@@ -38,7 +38,7 @@ var twitchFollows []*TwitchFollow
 
 func help(cmdMsg *quadlek.CommandMsg) {
 	cmdMsg.Command.Reply() <- &quadlek.CommandResp{
-		Text:      "twitch: report streamer activity.\nAvailable commands: help, live",
+		Text:      "twitch: report streamer activity.\nAvailable commands: help, live, clip <twitch user>",
 		InChannel: false,
 	}
 }
@@ -77,7 +77,7 @@ func twitchCommand(ctx context.Context, cmdChannel <-chan *quadlek.CommandMsg) {
 		case cmdMsg := <-cmdChannel:
 
 			// /twitch <command> <args...>
-			cmd := strings.SplitN(cmdMsg.Command.Text, " ", 1)
+			cmd := strings.SplitN(cmdMsg.Command.Text, " ", 2)
 			if len(cmd) == 0 {
 				help(cmdMsg)
 				return
@@ -85,15 +85,27 @@ func twitchCommand(ctx context.Context, cmdChannel <-chan *quadlek.CommandMsg) {
 			log.Infof("twitch: got command %s", cmd[0])
 			switch cmd[0] {
 			case "live":
+				gameIDs := []string{}
+				for _, follow := range twitchFollows {
+					if follow.stream != nil {
+						gameIDs = append(gameIDs, follow.stream.GameID)
+					}
+				}
+				games, err := twitchClient.GetGames(ctx, gameIDs)
+				if err != nil {
+					log.WithError(err).Error("twitch: failed fetching games for live streams")
+				}
+
 				count := 0
 				msg := ""
 				for _, follow := range twitchFollows {
 					if follow.stream != nil {
 						count += 1
 						gameName := "unknown"
-						game, err := twitchClient.GetGameByID(follow.stream.GameID)
-						if err == nil {
-							gameName = game.Name
+						if games != nil {
+							if game, ok := games.Found[follow.stream.GameID]; ok {
+								gameName = game.Name
+							}
 						}
 						msg += fmt.Sprintf("\n%s is live (game: %s)", follow.user.DisplayName, gameName)
 					}
@@ -102,6 +114,12 @@ func twitchCommand(ctx context.Context, cmdChannel <-chan *quadlek.CommandMsg) {
 					Text:      fmt.Sprintf("%d followed users are live right now.%s", count, msg),
 					InChannel: true,
 				}
+			case "clip":
+				if len(cmd) < 2 {
+					help(cmdMsg)
+					continue
+				}
+				clip(ctx, cmdMsg, strings.TrimSpace(cmd[1]))
 			default:
 				help(cmdMsg)
 			}
@@ -114,13 +132,66 @@ func twitchCommand(ctx context.Context, cmdChannel <-chan *quadlek.CommandMsg) {
 	}
 }
 
+// clip clips the current moment of a followed, live stream.
+func clip(ctx context.Context, cmdMsg *quadlek.CommandMsg, twitchUser string) {
+	var follow *TwitchFollow
+	for _, f := range twitchFollows {
+		if f.user != nil && strings.EqualFold(f.TwitchUser, twitchUser) {
+			follow = f
+			break
+		}
+	}
+	if follow == nil {
+		say(cmdMsg, fmt.Sprintf("twitch: not following %s", twitchUser), false)
+		return
+	}
+	if follow.stream == nil {
+		say(cmdMsg, fmt.Sprintf("twitch: %s isn't live right now", follow.user.DisplayName), false)
+		return
+	}
+
+	c, err := twitchClient.CreateClip(ctx, follow.user.ID, false)
+	if err != nil {
+		log.WithError(err).Errorf("twitch: failed clipping %s", follow.TwitchUser)
+		sayError(cmdMsg, "failed creating clip", false)
+		return
+	}
+	say(cmdMsg, fmt.Sprintf("twitch: clipped %s! https://clips.twitch.tv/%s", follow.user.DisplayName, c.ID), true)
+}
+
+// lastVOD returns the url of the archive of a stream that just ended, or "" if there is none (e.g.
+// the broadcaster doesn't save past broadcasts).
+func lastVOD(follow *TwitchFollow, stream *libtwitch.Stream) string {
+	videos, err := twitchClient.GetVideos(follow.ctx, libtwitch.VideosQuery{
+		UserID: follow.user.ID,
+		Type:   libtwitch.VideoTypeArchive,
+		Sort:   "time",
+		First:  1,
+	}).Next(follow.ctx)
+	if err != nil {
+		log.WithError(err).Errorf("twitch: failed fetching VOD for %s", follow.TwitchUser)
+		return ""
+	}
+	if len(videos) == 0 || videos[0].StreamID != stream.ID {
+		return ""
+	}
+	return videos[0].URL
+}
+
 func watch(bot *quadlek.Bot, follow *TwitchFollow) {
 	for {
 		select {
 		case <-follow.ctx.Done():
 			return
 		case stream := <-follow.streamWatcher.Streams():
+			previous := follow.stream
 			follow.stream = stream
+
+			vod := ""
+			if stream == nil && previous != nil {
+				vod = lastVOD(follow, previous)
+			}
+
 			for _, scn := range follow.SlackChannels {
 				scid, err := bot.GetChannelId(scn)
 				if err != nil {
@@ -130,6 +201,8 @@ func watch(bot *quadlek.Bot, follow *TwitchFollow) {
 
 				if follow.stream != nil {
 					bot.Say(scid, fmt.Sprintf("twitch: %s is live!", follow.user.DisplayName))
+				} else if vod != "" {
+					bot.Say(scid, fmt.Sprintf("twitch: %s went offline, catch the VOD: %s", follow.user.DisplayName, vod))
 				}
 			}
 		case streamFollow := <-follow.followWatcher.Follows():
@@ -154,7 +227,7 @@ func load(ctx context.Context, follows []*TwitchFollow) func(bot *quadlek.Bot, s
 			follow.ctx = ctx
 
 			// Look up twitch user
-			user, err := twitchClient.GetUserByName(follow.TwitchUser)
+			user, err := twitchClient.GetUserByName(ctx, follow.TwitchUser)
 			if err != nil {
 				log.WithError(err).Errorf("twitch: failed fetching twitch user %s, skipping.", follow.TwitchUser)
 				continue
@@ -163,9 +236,9 @@ func load(ctx context.Context, follows []*TwitchFollow) func(bot *quadlek.Bot, s
 			follow.user = user
 
 			// Look up if they happen to be streaming right now
-			stream, err := twitchClient.GetStreamByUserID(user.ID)
+			stream, err := twitchClient.GetStreamByUserID(ctx, user.ID)
 			if err != nil {
-				if err != libtwitch.ErrNotFound {
+				if !errors.Is(err, libtwitch.ErrNotFound) {
 					log.WithError(err).Errorf("twitch: failed fetching twitch user %s stream, skipping.", follow.TwitchUser)
 					continue
 				}
@@ -177,7 +250,7 @@ func load(ctx context.Context, follows []*TwitchFollow) func(bot *quadlek.Bot, s
 			// (optionally) Start following stream events
 			if follow.WatchStream {
 				log.Infof("twitch: adding stream watcher for user %s", follow.TwitchUser)
-				sw, err := twitchClient.WatchStream(user.ID)
+				sw, err := twitchClient.WatchStream(ctx, user.ID)
 				if err != nil {
 					log.WithError(err).Errorf("twitch: failed to watch twitch user %s stream, skipping.", follow.TwitchUser)
 					continue
@@ -190,7 +263,7 @@ func load(ctx context.Context, follows []*TwitchFollow) func(bot *quadlek.Bot, s
 			// (optionally) Start following follow events
 			if follow.WatchFollows {
 				log.Infof("twitch: adding follow watcher for user %s", follow.TwitchUser)
-				sw, err := twitchClient.WatchFollows(user.ID)
+				sw, err := twitchClient.WatchFollows(ctx, user.ID)
 				if err != nil {
 					log.WithError(err).Errorf("twitch: failed to watch twitch user %s follows, skipping.", follow.TwitchUser)
 					continue
@@ -209,13 +282,18 @@ func load(ctx context.Context, follows []*TwitchFollow) func(bot *quadlek.Bot, s
 	}
 }
 
-func makeClient(ctx context.Context, oauthClientID, oauthSecret, webhookCallbackPath string, debug bool) (*libtwitch.TwitchClient, error) {
+func makeClient(ctx context.Context, oauthClientID, oauthSecret, webhookCallbackPath string, debug bool, opts ...libtwitch.Option) (*libtwitch.TwitchClient, error) {
 
 	if oauthClientID == "" {
 		return nil, errors.New("OAuth ClientID is required.")
 	}
 
-	c, err := libtwitch.NewTwitchClient(ctx, oauthClientID, oauthSecret, webhookCallbackPath, debug)
+	c, err := libtwitch.NewTwitchClient(ctx, oauthClientID, append([]libtwitch.Option{
+		libtwitch.WithClientSecret(oauthSecret),
+		libtwitch.WithCallbackURL(webhookCallbackPath),
+		libtwitch.WithDebug(debug),
+		libtwitch.WithCache(libtwitch.NewLRUCache(1000), libtwitch.DefaultCacheTTLs),
+	}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -223,9 +301,12 @@ func makeClient(ctx context.Context, oauthClientID, oauthSecret, webhookCallback
 	return c, nil
 }
 
-func Register(oauthClientID, oauthSecret, webhookCallbackPath string, debug bool, follows []*TwitchFollow) quadlek.Plugin {
+// Register creates the twitch plugin. Additional client options are applied last, e.g. pass
+// libtwitch.WithTokenStore with the bot's twitch user to enable commands that need a user token
+// (clip requires the clips:edit scope).
+func Register(oauthClientID, oauthSecret, webhookCallbackPath string, debug bool, follows []*TwitchFollow, opts ...libtwitch.Option) quadlek.Plugin {
 	ctx, cancel := context.WithCancel(context.Background())
-	client, err := makeClient(ctx, oauthClientID, oauthSecret, webhookCallbackPath, debug)
+	client, err := makeClient(ctx, oauthClientID, oauthSecret, webhookCallbackPath, debug, opts...)
 	if err != nil {
 		log.WithError(err).Errorf("twitch: failed to create twitch client, bailing: %s", err)
 		return nil
@@ -0,0 +1,129 @@
+package libtwitch
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRateLimitWaits is how many times a request is re-sent after a 429 before the response is
+	// handed back to the caller.
+	maxRateLimitWaits = 3
+
+	// rateLimitBackoff is how long to wait after a 429 that didn't report the bucket state.
+	rateLimitBackoff = time.Second
+)
+
+// RateLimit is the state of the helix token bucket as reported by the Ratelimit-* headers.
+type RateLimit struct {
+	Limit     int       // Bucket size
+	Remaining int       // Points left in the bucket
+	Reset     time.Time // When the bucket is refilled
+}
+
+// rateLimiter tracks the helix token bucket and delays requests when it is empty.
+type rateLimiter struct {
+	mtx   sync.Mutex
+	state RateLimit
+	known bool
+}
+
+// wait blocks until the bucket has a point to spend (or ctx is done) and reserves it.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	for {
+		r.mtx.Lock()
+		if !r.known {
+			r.mtx.Unlock()
+			return nil
+		}
+
+		now := time.Now()
+		if !r.state.Reset.After(now) && r.state.Remaining <= 0 {
+			// The bucket refilled, we'll get the real numbers with the next response.
+			r.state.Remaining = max(r.state.Limit, 1)
+		}
+
+		if r.state.Remaining > 0 {
+			r.state.Remaining--
+			r.mtx.Unlock()
+			return nil
+		}
+
+		d := r.state.Reset.Sub(now)
+		r.mtx.Unlock()
+
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// parseRateLimit reads the Ratelimit-* headers, returning false if any of them is missing.
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("Ratelimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("Ratelimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	return RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}, true
+}
+
+// update syncs the bucket with the Ratelimit-* response headers. Responses without them (e.g.
+// from the OAuth endpoints) are ignored.
+func (r *rateLimiter) update(header http.Header) {
+	rl, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.state = rl
+	r.known = true
+}
+
+// exhaust empties the bucket after a 429, so the next wait blocks until the reset. It returns
+// false if the bucket state is unknown (twitch never sent Ratelimit-* headers), in which case the
+// caller has to back off on its own.
+func (r *rateLimiter) exhaust() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if !r.known {
+		return false
+	}
+	r.state.Remaining = 0
+	if !r.state.Reset.After(time.Now()) {
+		r.state.Reset = time.Now().Add(rateLimitBackoff)
+	}
+	return true
+}
+
+func (r *rateLimiter) get() RateLimit {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.state
+}
+
+// RateLimit returns the last known state of the helix rate limit bucket. It is zero until the
+// first API response is received.
+func (c *TwitchClient) RateLimit() RateLimit {
+	return c.ratelimit.get()
+}
@@ -0,0 +1,116 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	h := http.Header{}
+	h.Set("Ratelimit-Limit", "800")
+	h.Set("Ratelimit-Remaining", "799")
+	h.Set("Ratelimit-Reset", "1600000000")
+
+	rl, ok := parseRateLimit(h)
+	if !ok {
+		t.Fatal("expected headers to parse")
+	}
+	if rl.Limit != 800 || rl.Remaining != 799 || !rl.Reset.Equal(time.Unix(1600000000, 0)) {
+		t.Errorf("unexpected rate limit: %+v", rl)
+	}
+
+	h.Del("Ratelimit-Reset")
+	if _, ok := parseRateLimit(h); ok {
+		t.Error("expected incomplete headers not to parse")
+	}
+}
+
+func TestRateLimitWaitsForReset(t *testing.T) {
+	var r rateLimiter
+	h := http.Header{}
+	h.Set("Ratelimit-Limit", "10")
+	h.Set("Ratelimit-Remaining", "0")
+	h.Set("Ratelimit-Reset", strconv.FormatInt(time.Now().Add(2*time.Second).Unix(), 10))
+	r.update(h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := r.wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected wait to block until the reset, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := r.wait(ctx); err != nil {
+		t.Fatalf("expected wait to return after the reset, got %v", err)
+	}
+	if got := r.get().Remaining; got != 9 {
+		t.Errorf("expected the bucket to refill to 9 remaining, got %d", got)
+	}
+}
+
+func TestRateLimit429WithoutHeaders(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"1","login":"foo"}]}`)
+	}))
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		u, err := c.GetUserByID(ctx, strconv.Itoa(i+1))
+		cancel()
+		if err != nil {
+			t.Fatalf("request %d: %s", i, err)
+		}
+		if u.Login != "foo" {
+			t.Errorf("request %d: unexpected user %+v", i, u)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestRateLimitTracksHeaders(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Limit", "800")
+		w.Header().Set("Ratelimit-Remaining", "42")
+		w.Header().Set("Ratelimit-Reset", strconv.FormatInt(reset, 10))
+		fmt.Fprint(w, `{"data":[{"id":"1","login":"foo"}]}`)
+	}))
+
+	if _, err := c.GetUserByID(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	rl := c.RateLimit()
+	if rl.Limit != 800 || rl.Remaining != 42 || rl.Reset.Unix() != reset {
+		t.Errorf("unexpected rate limit: %+v", rl)
+	}
+}
+
+func TestRateLimit429NotRetriedTwice(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+	_, err := c.GetUserByID(context.Background(), "1")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != maxRateLimitWaits+1 {
+		t.Errorf("expected %d requests, got %d", maxRateLimitWaits+1, got)
+	}
+}
@@ -0,0 +1,155 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how transient failures (network errors and retryable status codes) are
+// retried. GET requests and token acquisition are always retried according to the policy, other
+// methods only when RetryNonIdempotent is set. Webhook (re)subscriptions are retried with the
+// policy as well.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first one, <= 1 disables retries
+	BaseDelay   time.Duration // Backoff before the first retry, doubled for every attempt
+	MaxDelay    time.Duration // Upper bound of a single backoff
+	Jitter      float64       // Fraction [0, 1] of each backoff that is randomized
+
+	// RetryableStatus reports whether a response status is worth retrying. Defaults to
+	// DefaultRetryableStatus.
+	RetryableStatus func(status int) bool
+
+	// RetryNonIdempotent allows retrying POST/PUT/PATCH/DELETE requests.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy retries idempotent requests up to 3 times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	BaseDelay:       250 * time.Millisecond,
+	MaxDelay:        5 * time.Second,
+	Jitter:          0.5,
+	RetryableStatus: DefaultRetryableStatus,
+}
+
+// NoRetryPolicy never retries.
+var NoRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// DefaultRetryableStatus retries 429s and 5xx responses. API requests never retry 429s though,
+// they are re-sent by the rate limiter once the bucket resets instead.
+func DefaultRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// WithRetryPolicy sets the retry policy used for API calls, token acquisition and webhook
+// subscriptions (default DefaultRetryPolicy).
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *TwitchClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// permanentError marks an error that must not be retried, e.g. a failed authentication inside
+// an API request which has already been retried on its own.
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Unwrap() error {
+	return e.error
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// allows reports whether a request may be retried at all.
+func (p RetryPolicy) allows(idempotent bool) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	return idempotent || p.RetryNonIdempotent
+}
+
+func (p RetryPolicy) retryableStatus(status int) bool {
+	if p.RetryableStatus == nil {
+		return DefaultRetryableStatus(status)
+	}
+	return p.RetryableStatus(status)
+}
+
+// backoff returns how long to wait before the given retry (1 for the first retry).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		j := time.Duration(float64(d) * p.Jitter)
+		d = d - j + time.Duration(rand.Int63n(int64(j)+1))
+	}
+	return d
+}
+
+// sleep waits for the given retry's backoff, or until ctx is done.
+func (p RetryPolicy) sleep(ctx context.Context, retry int) error {
+	t := time.NewTimer(p.backoff(retry))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retry calls fn until it succeeds, returns a non-retryable result, or the policy runs out of
+// attempts. fn returns the response status (0 if there was none) and any error; an error without
+// a status is treated as a transient network failure unless it is a permanentError.
+func (c *TwitchClient) retry(ctx context.Context, idempotent bool, what string, fn func() (int, error)) error {
+	policy := c.retryPolicy
+	for attempt := 1; ; attempt++ {
+		status, err := fn()
+
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.error
+		}
+
+		var apiErr *APIError
+		if status == 0 && errors.As(err, &apiErr) {
+			status = apiErr.StatusCode
+		}
+
+		retryable := false
+		switch {
+		case err != nil && status == 0:
+			retryable = ctx.Err() == nil
+		case status != 0:
+			retryable = policy.retryableStatus(status)
+		}
+
+		if !retryable || !policy.allows(idempotent) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		c.log("retry(%s): attempt %d failed (status: %d, err: %v)", what, attempt, status, err)
+		if serr := policy.sleep(ctx, attempt); serr != nil {
+			if err != nil {
+				return err
+			}
+			return serr
+		}
+	}
+}
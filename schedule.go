@@ -0,0 +1,261 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Schedule is a page of a broadcaster's stream schedule.
+type Schedule struct {
+	Segments         []*ScheduleSegment `json:"segments"`
+	BroadcasterID    string             `json:"broadcaster_id"`
+	BroadcasterName  string             `json:"broadcaster_name"`
+	BroadcasterLogin string             `json:"broadcaster_login"`
+	Vacation         *ScheduleVacation  `json:"vacation"` // Nil unless vacation is enabled
+}
+
+// ScheduleSegment is a scheduled broadcast. Occurrences of recurring segments are returned
+// individually. The broadcaster fields are copied from the schedule it belongs to, so segments of
+// several schedules can be merged (see ICalendar).
+type ScheduleSegment struct {
+	ID            string            `json:"id"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       time.Time         `json:"end_time"`
+	Title         string            `json:"title"`
+	CanceledUntil *time.Time        `json:"canceled_until"` // Nil unless the broadcast is canceled
+	Category      *ScheduleCategory `json:"category"`
+	IsRecurring   bool              `json:"is_recurring"`
+
+	BroadcasterID    string `json:"-"`
+	BroadcasterName  string `json:"-"`
+	BroadcasterLogin string `json:"-"`
+}
+
+type ScheduleCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ScheduleVacation struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ScheduleQuery selects the segments GetScheduleSegments returns. BroadcasterID is required.
+type ScheduleQuery struct {
+	BroadcasterID string
+	IDs           []string
+	StartTime     time.Time // Only segments starting at or after this time, defaults to now
+	First         int       // Page size, up to 25
+	After         string    // Cursor to resume from, see Paginator.Cursor
+}
+
+func (q ScheduleQuery) values() url.Values {
+	v := url.Values{"broadcaster_id": []string{q.BroadcasterID}}
+	if len(q.IDs) > 0 {
+		v["id"] = q.IDs
+	}
+	if !q.StartTime.IsZero() {
+		v.Set("start_time", q.StartTime.UTC().Format(time.RFC3339))
+	}
+	if q.First > 0 {
+		v.Set("first", strconv.Itoa(q.First))
+	}
+	return v
+}
+
+// ScheduleSegmentCreate describes a segment to add with CreateScheduleSegment. Timezone is an IANA
+// time zone name (e.g. "America/New_York") that recurring segments keep their time in.
+type ScheduleSegmentCreate struct {
+	StartTime   time.Time
+	Timezone    string
+	Duration    time.Duration // Rounded to minutes, between 30m and 24h
+	IsRecurring bool
+	CategoryID  string
+	Title       string
+}
+
+func (s ScheduleSegmentCreate) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"start_time":   s.StartTime.UTC().Format(time.RFC3339),
+		"timezone":     s.Timezone,
+		"duration":     strconv.Itoa(int(s.Duration.Round(time.Minute) / time.Minute)),
+		"is_recurring": s.IsRecurring,
+	}
+	if s.CategoryID != "" {
+		m["category_id"] = s.CategoryID
+	}
+	if s.Title != "" {
+		m["title"] = s.Title
+	}
+	return json.Marshal(m)
+}
+
+// ScheduleSegmentUpdate holds the segment properties to change with UpdateScheduleSegment. Nil
+// fields are left unchanged.
+type ScheduleSegmentUpdate struct {
+	StartTime  *time.Time
+	Duration   *time.Duration
+	CategoryID *string
+	Title      *string
+	IsCanceled *bool
+	Timezone   *string
+}
+
+func (u ScheduleSegmentUpdate) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	if u.StartTime != nil {
+		m["start_time"] = u.StartTime.UTC().Format(time.RFC3339)
+	}
+	if u.Duration != nil {
+		m["duration"] = strconv.Itoa(int(u.Duration.Round(time.Minute) / time.Minute))
+	}
+	if u.CategoryID != nil {
+		m["category_id"] = *u.CategoryID
+	}
+	if u.Title != nil {
+		m["title"] = *u.Title
+	}
+	if u.IsCanceled != nil {
+		m["is_canceled"] = *u.IsCanceled
+	}
+	if u.Timezone != nil {
+		m["timezone"] = *u.Timezone
+	}
+	return json.Marshal(m)
+}
+
+// decodeSchedule parses the "data" field of schedule responses, which is a single object rather
+// than a list.
+func decodeSchedule(data []byte) (*Schedule, error) {
+	schedule := &Schedule{}
+	if len(data) > 0 {
+		err := json.Unmarshal(data, schedule)
+		if err != nil {
+			return nil, NewTwitchClientError("failed to parse response", err)
+		}
+	}
+	for _, s := range schedule.Segments {
+		s.BroadcasterID = schedule.BroadcasterID
+		s.BroadcasterName = schedule.BroadcasterName
+		s.BroadcasterLogin = schedule.BroadcasterLogin
+	}
+	return schedule, nil
+}
+
+// GetSchedule fetches the first page of a broadcaster's schedule along with its vacation
+// settings, see GetScheduleSegments for the rest of the segments. It returns ErrNotFound if the
+// broadcaster has no schedule.
+func (c *TwitchClient) GetSchedule(ctx context.Context, broadcasterID string) (*Schedule, error) {
+	params := ScheduleQuery{BroadcasterID: broadcasterID}.values()
+	_, body, err := c.RequestContext(ctx, "GET", "schedule", &params, nil)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+	return decodeSchedule(body)
+}
+
+// GetScheduleSegments lists the segments of a broadcaster's schedule.
+func (c *TwitchClient) GetScheduleSegments(ctx context.Context, q ScheduleQuery) *Paginator[*ScheduleSegment] {
+	p := newPaginator[*ScheduleSegment](c, "schedule", q.values()).bind(ctx)
+	p.cursor = q.After
+	p.maxFirst = 25 // Schedule pages hold up to 25 segments
+	p.decode = func(data []byte) ([]*ScheduleSegment, error) {
+		schedule, err := decodeSchedule(data)
+		if err != nil {
+			return nil, err
+		}
+		return schedule.Segments, nil
+	}
+	return p
+}
+
+// CreateScheduleSegment adds a segment to the broadcaster's schedule. It requires a user token of
+// the broadcaster with the channel:manage:schedule scope.
+func (c *TwitchClient) CreateScheduleSegment(ctx context.Context, broadcasterID string, segment ScheduleSegmentCreate) (*ScheduleSegment, error) {
+	params := &url.Values{"broadcaster_id": []string{broadcasterID}}
+	_, body, err := c.RequestContext(ctx, "POST", "schedule/segment", params, segment)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+	return firstSegment(body)
+}
+
+// UpdateScheduleSegment changes a segment of the broadcaster's schedule. It requires a user token
+// of the broadcaster with the channel:manage:schedule scope.
+func (c *TwitchClient) UpdateScheduleSegment(ctx context.Context, broadcasterID, id string, update ScheduleSegmentUpdate) (*ScheduleSegment, error) {
+	params := &url.Values{"broadcaster_id": []string{broadcasterID}, "id": []string{id}}
+	_, body, err := c.RequestContext(ctx, "PATCH", "schedule/segment", params, update)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+	return firstSegment(body)
+}
+
+func firstSegment(data []byte) (*ScheduleSegment, error) {
+	schedule, err := decodeSchedule(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedule.Segments) == 0 {
+		return nil, NewTwitchClientError("no segment returned", nil)
+	}
+	return schedule.Segments[0], nil
+}
+
+// DeleteScheduleSegment removes a segment from the broadcaster's schedule, for recurring
+// segments that includes all of its occurrences. It requires a user token of the broadcaster
+// with the channel:manage:schedule scope.
+func (c *TwitchClient) DeleteScheduleSegment(ctx context.Context, broadcasterID, id string) error {
+	params := &url.Values{"broadcaster_id": []string{broadcasterID}, "id": []string{id}}
+	resp, _, err := c.RequestContext(ctx, "DELETE", "schedule/segment", params, nil)
+	if err != nil {
+		return NewTwitchClientError("error making request", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		return NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// SetScheduleVacation enables vacation mode for the given period (in the IANA time zone
+// timezone), or disables it if vacation is nil. It requires a user token of the broadcaster with
+// the channel:manage:schedule scope.
+func (c *TwitchClient) SetScheduleVacation(ctx context.Context, broadcasterID string, vacation *ScheduleVacation, timezone string) error {
+	params := &url.Values{
+		"broadcaster_id":      []string{broadcasterID},
+		"is_vacation_enabled": []string{strconv.FormatBool(vacation != nil)},
+	}
+	if vacation != nil {
+		params.Set("vacation_start_time", vacation.StartTime.UTC().Format(time.RFC3339))
+		params.Set("vacation_end_time", vacation.EndTime.UTC().Format(time.RFC3339))
+		params.Set("timezone", timezone)
+	}
+
+	resp, _, err := c.RequestContext(ctx, "PATCH", "schedule/settings", params, nil)
+	if err != nil {
+		return NewTwitchClientError("error making request", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		return NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// GetScheduleICalendar fetches a broadcaster's schedule as an iCalendar (RFC 5545) document, as
+// served by twitch.
+func (c *TwitchClient) GetScheduleICalendar(ctx context.Context, broadcasterID string) ([]byte, error) {
+	params := &url.Values{"broadcaster_id": []string{broadcasterID}}
+	_, body, err := c.RequestRaw(ctx, "GET", "schedule/icalendar", params, nil)
+	if err != nil {
+		return nil, NewTwitchClientError("error making request", err)
+	}
+	return body, nil
+}
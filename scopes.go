@@ -0,0 +1,189 @@
+package libtwitch
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Scope is an OAuth scope, see https://dev.twitch.tv/docs/authentication/scopes
+type Scope string
+
+const (
+	ScopeAnalyticsReadExtensions      Scope = "analytics:read:extensions"
+	ScopeAnalyticsReadGames           Scope = "analytics:read:games"
+	ScopeBitsRead                     Scope = "bits:read"
+	ScopeChannelEditCommercial        Scope = "channel:edit:commercial"
+	ScopeChannelManageBroadcast       Scope = "channel:manage:broadcast"
+	ScopeChannelManageModerators      Scope = "channel:manage:moderators"
+	ScopeChannelManageRaids           Scope = "channel:manage:raids"
+	ScopeChannelManageSchedule        Scope = "channel:manage:schedule"
+	ScopeChannelManageVideos          Scope = "channel:manage:videos"
+	ScopeChannelManageVIPs            Scope = "channel:manage:vips"
+	ScopeChannelReadEditors           Scope = "channel:read:editors"
+	ScopeChannelReadSubscriptions     Scope = "channel:read:subscriptions"
+	ScopeChannelReadVIPs              Scope = "channel:read:vips"
+	ScopeClipsEdit                    Scope = "clips:edit"
+	ScopeModerationRead               Scope = "moderation:read"
+	ScopeModeratorManageAutoMod       Scope = "moderator:manage:automod"
+	ScopeModeratorManageAutoModSets   Scope = "moderator:manage:automod_settings"
+	ScopeModeratorManageBannedUsers   Scope = "moderator:manage:banned_users"
+	ScopeModeratorManageBlockedTerms  Scope = "moderator:manage:blocked_terms"
+	ScopeModeratorManageChatMessages  Scope = "moderator:manage:chat_messages"
+	ScopeModeratorReadAutoModSettings Scope = "moderator:read:automod_settings"
+	ScopeModeratorReadBlockedTerms    Scope = "moderator:read:blocked_terms"
+	ScopeModeratorReadFollowers       Scope = "moderator:read:followers"
+	ScopeUserEdit                     Scope = "user:edit"
+	ScopeUserReadEmail                Scope = "user:read:email"
+	ScopeUserReadFollows              Scope = "user:read:follows"
+)
+
+// endpointScopes lists the scopes a user token needs for helix endpoints, keyed by
+// "METHOD path". Any one of an endpoint's scopes is enough, endpoints that aren't listed don't
+// need any.
+var endpointScopes = map[string][]Scope{
+	"PUT users":                       {ScopeUserEdit},
+	"PATCH channels":                  {ScopeChannelManageBroadcast},
+	"GET channels/editors":            {ScopeChannelReadEditors},
+	"POST channels/commercial":        {ScopeChannelEditCommercial},
+	"GET subscriptions":               {ScopeChannelReadSubscriptions},
+	"GET bits/leaderboard":            {ScopeBitsRead},
+	"POST clips":                      {ScopeClipsEdit},
+	"DELETE videos":                   {ScopeChannelManageVideos},
+	"POST raids":                      {ScopeChannelManageRaids},
+	"DELETE raids":                    {ScopeChannelManageRaids},
+	"GET analytics/extensions":        {ScopeAnalyticsReadExtensions},
+	"GET analytics/games":             {ScopeAnalyticsReadGames},
+	"GET channels/followed":           {ScopeUserReadFollows},
+	"POST schedule/segment":           {ScopeChannelManageSchedule},
+	"PATCH schedule/segment":          {ScopeChannelManageSchedule},
+	"DELETE schedule/segment":         {ScopeChannelManageSchedule},
+	"PATCH schedule/settings":         {ScopeChannelManageSchedule},
+	"POST moderation/bans":            {ScopeModeratorManageBannedUsers},
+	"DELETE moderation/bans":          {ScopeModeratorManageBannedUsers},
+	"GET moderation/banned":           {ScopeModerationRead, ScopeModeratorManageBannedUsers},
+	"GET moderation/moderators":       {ScopeModerationRead, ScopeChannelManageModerators},
+	"POST moderation/moderators":      {ScopeChannelManageModerators},
+	"DELETE moderation/moderators":    {ScopeChannelManageModerators},
+	"GET channels/vips":               {ScopeChannelReadVIPs, ScopeChannelManageVIPs},
+	"POST channels/vips":              {ScopeChannelManageVIPs},
+	"DELETE channels/vips":            {ScopeChannelManageVIPs},
+	"GET moderation/blocked_terms":    {ScopeModeratorReadBlockedTerms, ScopeModeratorManageBlockedTerms},
+	"POST moderation/blocked_terms":   {ScopeModeratorManageBlockedTerms},
+	"DELETE moderation/blocked_terms": {ScopeModeratorManageBlockedTerms},
+	"GET moderation/automod/settings": {ScopeModeratorReadAutoModSettings, ScopeModeratorManageAutoModSets},
+	"PUT moderation/automod/settings": {ScopeModeratorManageAutoModSets},
+	"POST moderation/automod/message": {ScopeModeratorManageAutoMod},
+	"DELETE moderation/chat":          {ScopeModeratorManageChatMessages},
+}
+
+// ScopeSet is a set of OAuth scopes. It (un)marshals as a JSON array, and also accepts the space
+// separated string form twitch uses in some places.
+type ScopeSet map[Scope]struct{}
+
+func NewScopeSet(scopes ...Scope) ScopeSet {
+	s := make(ScopeSet, len(scopes))
+	for _, scope := range scopes {
+		s[scope] = struct{}{}
+	}
+	return s
+}
+
+func (s ScopeSet) Has(scope Scope) bool {
+	_, ok := s[scope]
+	return ok
+}
+
+// Missing returns the scopes in required that aren't in the set.
+func (s ScopeSet) Missing(required ...Scope) []Scope {
+	var missing []Scope
+	for _, scope := range required {
+		if !s.Has(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// HasAny reports whether the set contains at least one of the given scopes.
+func (s ScopeSet) HasAny(scopes ...Scope) bool {
+	for _, scope := range scopes {
+		if s.Has(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Slice returns the scopes in sorted order.
+func (s ScopeSet) Slice() []Scope {
+	scopes := make([]Scope, 0, len(s))
+	for scope := range s {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i] < scopes[j] })
+	return scopes
+}
+
+func (s ScopeSet) String() string {
+	return joinScopes(s.Slice())
+}
+
+func (s ScopeSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Slice())
+}
+
+func (s *ScopeSet) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*s = nil
+		return nil
+	}
+
+	var scopes []Scope
+	if err := json.Unmarshal(b, &scopes); err != nil {
+		var str string
+		if serr := json.Unmarshal(b, &str); serr != nil {
+			return err
+		}
+		for _, scope := range strings.Fields(str) {
+			scopes = append(scopes, Scope(scope))
+		}
+	}
+	*s = NewScopeSet(scopes...)
+	return nil
+}
+
+func joinScopes(scopes []Scope) string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return strings.Join(s, " ")
+}
+
+// checkScopes returns an *ErrMissingScope if the client's token can't have any of the scopes the
+// given endpoint accepts. Only app tokens (which never have user scopes) and user tokens with
+// known scopes are checked.
+func (c *TwitchClient) checkScopes(method, path string) error {
+	accepted := endpointScopes[method+" "+path]
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	c.tokenMtx.Lock()
+	userMode, token := c.userMode, c.token
+	c.tokenMtx.Unlock()
+
+	missing := false
+	switch {
+	case !userMode:
+		missing = true
+	case token != nil && token.Scopes != nil:
+		missing = !token.Scopes.HasAny(accepted...)
+	}
+
+	if missing {
+		return &ErrMissingScope{Method: method, Path: path, Required: accepted}
+	}
+	return nil
+}
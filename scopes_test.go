@@ -0,0 +1,50 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckScopesAcceptsAlternatives(t *testing.T) {
+	newClient := func(scopes ...Scope) *TwitchClient {
+		c, err := NewTwitchClient(context.Background(), "client-id",
+			WithUserToken(&AccessToken{AccessToken: "token", Scopes: NewScopeSet(scopes...)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(c.Close)
+		return c
+	}
+
+	for _, scope := range []Scope{ScopeChannelReadVIPs, ScopeChannelManageVIPs} {
+		if err := newClient(scope).checkScopes("GET", "channels/vips"); err != nil {
+			t.Errorf("%s: unexpected error: %s", scope, err)
+		}
+	}
+
+	err := newClient(ScopeUserReadFollows).checkScopes("GET", "channels/vips")
+	var missing *ErrMissingScope
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected an *ErrMissingScope, got %v", err)
+	}
+	if len(missing.Required) != 2 {
+		t.Errorf("expected both alternatives to be reported, got %v", missing.Required)
+	}
+}
+
+func TestCheckScopesAppToken(t *testing.T) {
+	c, err := NewTwitchClient(context.Background(), "client-id", WithClientSecret("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var missing *ErrMissingScope
+	if err := c.checkScopes("POST", "clips"); !errors.As(err, &missing) {
+		t.Errorf("expected an *ErrMissingScope for an app token, got %v", err)
+	}
+	if err := c.checkScopes("GET", "users"); err != nil {
+		t.Errorf("unexpected error for an endpoint without scopes: %s", err)
+	}
+}
@@ -0,0 +1,54 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ChannelSearchResult is a channel matching SearchChannels. StartedAt is zero for channels that
+// aren't live.
+type ChannelSearchResult struct {
+	ID                  string    `json:"id"`
+	BroadcasterLogin    string    `json:"broadcaster_login"`
+	DisplayName         string    `json:"display_name"`
+	BroadcasterLanguage string    `json:"broadcaster_language"`
+	GameID              string    `json:"game_id"`
+	GameName            string    `json:"game_name"`
+	Title               string    `json:"title"`
+	Tags                []string  `json:"tags"`
+	ThumbnailURL        string    `json:"thumbnail_url"`
+	IsLive              bool      `json:"is_live"`
+	StartedAt           time.Time `json:"started_at"`
+}
+
+func (r *ChannelSearchResult) UnmarshalJSON(b []byte) error {
+	type alias ChannelSearchResult
+	aux := struct {
+		*alias
+		StartedAt string `json:"started_at"`
+	}{alias: (*alias)(r)}
+
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+	r.StartedAt, err = parseOptionalTime(aux.StartedAt)
+	return err
+}
+
+// SearchCategories finds games and categories whose name matches the query.
+func (c *TwitchClient) SearchCategories(ctx context.Context, query string) *Paginator[*Game] {
+	return newPaginator[*Game](c, "search/categories", url.Values{"query": []string{query}}).bind(ctx)
+}
+
+// SearchChannels finds channels whose login or display name matches the query, optionally only
+// live ones.
+func (c *TwitchClient) SearchChannels(ctx context.Context, query string, liveOnly bool) *Paginator[*ChannelSearchResult] {
+	return newPaginator[*ChannelSearchResult](c, "search/channels", url.Values{
+		"query":     []string{query},
+		"live_only": []string{strconv.FormatBool(liveOnly)},
+	}).bind(ctx)
+}
@@ -0,0 +1,298 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiry a cached access token is replaced.
+const tokenRefreshMargin = 5 * time.Minute
+
+type AccessToken struct {
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	ExpiresIn    int      `json:"expires_in"`
+	Scopes       ScopeSet `json:"scope,omitempty"`
+	TokenType    string   `json:"token_type,omitempty"`
+
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// fresh reports whether the token can still be used for a while.
+func (t *AccessToken) fresh() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.ExpiresAt.IsZero() || time.Now().Add(tokenRefreshMargin).Before(t.ExpiresAt)
+}
+
+// TokenValidation is the response of the OAuth2 validate endpoint.
+type TokenValidation struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	UserID    string   `json:"user_id"`
+	Scopes    ScopeSet `json:"scopes"`
+	ExpiresIn int      `json:"expires_in"`
+}
+
+// tokenFetch is an in-flight token request shared by everyone waiting on a new token.
+type tokenFetch struct {
+	done  chan struct{}
+	token *AccessToken
+	err   error
+}
+
+// authenticate adds credentials to the given request: a user access token (see SetUserToken) or
+// an OAuth2 app access token (fetched with the client id and secret) as the "Authorization"
+// header, or just the "Client-ID" header when neither is configured. The token used is returned so
+// it can be invalidated if it is rejected.
+func (c *TwitchClient) authenticate(ctx context.Context, request *http.Request) (*AccessToken, error) {
+
+	if c.clientID == "" {
+		return nil, errors.New("client id is required")
+	}
+	request.Header.Set("Client-ID", c.clientID)
+
+	if c.clientSecret == "" && !c.usingUserToken() {
+		c.log("auth: client secret not configured, using client id only")
+		return nil, nil
+	}
+
+	t, err := c.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.AccessToken))
+	return t, nil
+}
+
+// getToken returns the cached access token, fetching a new one if it is missing or about to
+// expire. Concurrent callers share a single fetch, which is bound to the client's lifetime so one
+// caller giving up doesn't fail the others.
+func (c *TwitchClient) getToken(ctx context.Context) (*AccessToken, error) {
+	c.tokenMtx.Lock()
+	if c.token.fresh() {
+		t := c.token
+		c.tokenMtx.Unlock()
+		return t, nil
+	}
+
+	f := c.tokenFetch
+	if f == nil {
+		f = &tokenFetch{done: make(chan struct{})}
+		c.tokenFetch = f
+		go c.fetchToken(f, c.userMode, c.token)
+	}
+	c.tokenMtx.Unlock()
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchToken gets a new access token: a newer one from the token store if there is one, otherwise
+// user tokens are refreshed with their refresh token and app tokens are requested with the client
+// credentials grant. New tokens are saved to the token store.
+func (c *TwitchClient) fetchToken(f *tokenFetch, userMode bool, current *AccessToken) {
+	var t *AccessToken
+	var err error
+
+	stored := c.loadToken(c.ctx)
+	if stored != nil && (current == nil || stored.AccessToken != current.AccessToken) {
+		if stored.fresh() {
+			c.log("using stored access token (expires: %s)", stored.ExpiresAt)
+			t = stored
+		} else if userMode && stored.RefreshToken != "" {
+			current = stored
+		}
+	}
+
+	switch {
+	case t != nil:
+	case userMode:
+		if current == nil || current.RefreshToken == "" {
+			err = NewTwitchClientError("user access token expired and cannot be refreshed", nil)
+		} else {
+			c.log("refreshing user access token")
+			t, err = c.RefreshUserToken(c.ctx, current.RefreshToken)
+			if err == nil {
+				c.saveToken(c.ctx, t)
+			}
+		}
+	default:
+		c.log("requesting new access token")
+		t, err = c.requestToken(c.ctx, url.Values{
+			"client_id":     []string{c.clientID},
+			"client_secret": []string{c.clientSecret},
+			"grant_type":    []string{"client_credentials"},
+		})
+		if err == nil {
+			c.saveToken(c.ctx, t)
+		}
+	}
+
+	c.tokenMtx.Lock()
+	if err == nil {
+		c.token = t
+		c.log("using new access token (expires: %s)", t.ExpiresAt)
+	}
+	c.tokenFetch = nil
+	c.tokenMtx.Unlock()
+
+	f.token, f.err = t, err
+	close(f.done)
+}
+
+// invalidateToken drops the cached token if it is still the given one, so the next request
+// fetches a new one. User tokens are only marked expired, their refresh token is still needed.
+func (c *TwitchClient) invalidateToken(t *AccessToken) {
+	c.tokenMtx.Lock()
+	if c.token != t {
+		c.tokenMtx.Unlock()
+		return
+	}
+	if c.userMode {
+		expired := *t
+		expired.ExpiresAt = time.Now()
+		c.token = &expired
+		c.tokenMtx.Unlock()
+		return
+	}
+	c.token = nil
+	c.tokenMtx.Unlock()
+
+	c.deleteToken(c.ctx)
+}
+
+// SetUserToken makes the client send requests on behalf of the user the token belongs to instead
+// of using an app access token. The token is refreshed with its refresh token when it expires.
+// If the token store was configured with a user id (see WithTokenStore) the token and every
+// refreshed one are saved to it, otherwise see UserToken to persist the rotated tokens.
+func (c *TwitchClient) SetUserToken(t *AccessToken) {
+	c.tokenMtx.Lock()
+	c.token = t
+	c.userMode = true
+	c.tokenMtx.Unlock()
+
+	if c.tokenUserID != "" {
+		c.saveToken(c.ctx, t)
+	}
+}
+
+// UserToken returns the current user access token, or nil if the client isn't using one.
+func (c *TwitchClient) UserToken() *AccessToken {
+	c.tokenMtx.Lock()
+	defer c.tokenMtx.Unlock()
+	if !c.userMode {
+		return nil
+	}
+	return c.token
+}
+
+func (c *TwitchClient) usingUserToken() bool {
+	c.tokenMtx.Lock()
+	defer c.tokenMtx.Unlock()
+	return c.userMode
+}
+
+// requestToken posts the given form to the OAuth2 token endpoint.
+func (c *TwitchClient) requestToken(ctx context.Context, form url.Values) (*AccessToken, error) {
+	_, body, err := c.oauthRequest(ctx, "POST", "token", form, "")
+	if err != nil {
+		return nil, NewTwitchClientError("authentication failed", err)
+	}
+
+	t := &AccessToken{}
+	err = json.Unmarshal(body, t)
+	if err != nil {
+		return nil, NewTwitchClientError("failed to parse token", err)
+	}
+	if t.AccessToken == "" {
+		return nil, NewTwitchClientError("authentication failed: no access token", nil)
+	}
+
+	if t.ExpiresIn > 0 {
+		t.ExpiresAt = time.Now().Add(time.Second * time.Duration(t.ExpiresIn))
+	}
+	return t, nil
+}
+
+// oauthRequest makes a request against the OAuth2 endpoints, retrying according to the client's
+// retry policy. The form is sent as the request body, authorization as the "Authorization" header.
+// Error responses are returned as an *APIError.
+func (c *TwitchClient) oauthRequest(ctx context.Context, method, path string, form url.Values, authorization string) (*http.Response, []byte, error) {
+	var resp *http.Response
+	var body []byte
+	err := c.retry(ctx, true, "oauth2/"+path, func() (int, error) {
+		var r *strings.Reader
+		if form != nil {
+			r = strings.NewReader(form.Encode())
+		} else {
+			r = strings.NewReader("")
+		}
+
+		request, err := http.NewRequestWithContext(ctx, method, c.oauthEndpoint+path, r)
+		if err != nil {
+			return 0, permanentError{err}
+		}
+		if form != nil {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		request.Header.Set("User-Agent", c.userAgent)
+
+		resp, body, err = c.doRequest(request)
+		if err != nil {
+			return 0, err
+		}
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return resp, nil, newAPIError(method, "oauth2/"+path, resp, body)
+	}
+	return resp, body, nil
+}
+
+// ValidateToken checks the client's current access token against the OAuth2 validate endpoint.
+// A token twitch no longer accepts is dropped, so the next request fetches a new one.
+func (c *TwitchClient) ValidateToken(ctx context.Context) (*TokenValidation, error) {
+	if c.clientSecret == "" && !c.usingUserToken() {
+		return nil, NewTwitchClientError("no access token configured", nil)
+	}
+
+	t, err := c.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, body, err := c.oauthRequest(ctx, "GET", "validate", nil, "OAuth "+t.AccessToken)
+	if err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			c.log("auth: access token is no longer valid, invalidating")
+			c.invalidateToken(t)
+		}
+		return nil, NewTwitchClientError("token validation failed", err)
+	}
+
+	v := &TokenValidation{}
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return nil, NewTwitchClientError("failed to parse response", err)
+	}
+	return v, nil
+}
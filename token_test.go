@@ -0,0 +1,151 @@
+package libtwitch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer issues numbered access tokens ("token-1", "token-2", ...) from /oauth2/token and
+// passes everything else to api.
+type tokenServer struct {
+	issued int32
+	api    http.HandlerFunc
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/oauth2/token" {
+		s.api(w, r)
+		return
+	}
+	n := atomic.AddInt32(&s.issued, 1)
+	_ = r.ParseForm()
+	refresh := ""
+	if r.Form.Get("grant_type") == "refresh_token" {
+		refresh = fmt.Sprintf(`,"refresh_token":"refresh-%d"`, n)
+	}
+	fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600,"token_type":"bearer"%s}`, n, refresh)
+}
+
+func userResponse(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, `{"data":[{"id":%q,"login":"user%s"}]}`, r.URL.Query().Get("id"), r.URL.Query().Get("id"))
+}
+
+func TestTokenFetchIsShared(t *testing.T) {
+	var unauthorized int32
+	s := &tokenServer{api: func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" || r.Header.Get("Client-ID") != "client-id" {
+			atomic.AddInt32(&unauthorized, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		userResponse(w, r)
+	}}
+	c := newTestClient(t, s, WithClientSecret("secret"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if _, err := c.GetUserByID(context.Background(), id); err != nil {
+				t.Errorf("GetUserByID(%s): %s", id, err)
+			}
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&s.issued); got != 1 {
+		t.Errorf("expected a single token request, got %d", got)
+	}
+	if got := atomic.LoadInt32(&unauthorized); got != 0 {
+		t.Errorf("expected all requests to be authorized, got %d rejected", got)
+	}
+}
+
+func TestTokenInvalidatedOn401(t *testing.T) {
+	var requests int32
+	s := &tokenServer{api: func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}`)
+			return
+		}
+		userResponse(w, r)
+	}}
+	c := newTestClient(t, s, WithClientSecret("secret"))
+
+	u, err := c.GetUserByID(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != "1" {
+		t.Errorf("unexpected user %+v", u)
+	}
+	if got := atomic.LoadInt32(&s.issued); got != 2 {
+		t.Errorf("expected the rejected token to be replaced, got %d token requests", got)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected the request to be sent twice, got %d", got)
+	}
+}
+
+func TestTokenInvalidatedOnlyOnce(t *testing.T) {
+	s := &tokenServer{api: func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}}
+	c := newTestClient(t, s, WithClientSecret("secret"))
+
+	_, err := c.GetUserByID(context.Background(), "1")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := atomic.LoadInt32(&s.issued); got != 2 {
+		t.Errorf("expected one retry with a new token, got %d token requests", got)
+	}
+}
+
+func TestUserTokenRefresh(t *testing.T) {
+	s := &tokenServer{api: func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		userResponse(w, r)
+	}}
+	c := newTestClient(t, s, WithUserToken(&AccessToken{
+		AccessToken:  "expired",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}))
+
+	if _, err := c.GetUserByID(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	got := c.UserToken()
+	if got.AccessToken != "token-1" || got.RefreshToken != "refresh-1" {
+		t.Errorf("expected the refreshed token to be in use, got %+v", got)
+	}
+}
+
+func TestUserTokenWithoutRefreshToken(t *testing.T) {
+	s := &tokenServer{api: userResponse}
+	c := newTestClient(t, s, WithUserToken(&AccessToken{
+		AccessToken: "expired",
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}))
+
+	if _, err := c.GetUserByID(context.Background(), "1"); err == nil {
+		t.Fatal("expected an error for an expired token without a refresh token")
+	}
+	if got := atomic.LoadInt32(&s.issued); got != 0 {
+		t.Errorf("expected no token requests, got %d", got)
+	}
+}
@@ -0,0 +1,310 @@
+package libtwitch
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrTokenNotFound = NewTwitchClientError("token not found", nil)
+
+// TokenKey identifies a stored token. App access tokens have an empty UserID.
+type TokenKey struct {
+	ClientID string
+	UserID   string
+}
+
+func (k TokenKey) String() string {
+	if k.UserID == "" {
+		return k.ClientID + "/app"
+	}
+	return k.ClientID + "/" + k.UserID
+}
+
+// TokenStore persists access tokens, so they survive restarts and can be shared between
+// processes using the same client id. Load returns ErrTokenNotFound for unknown keys.
+type TokenStore interface {
+	Load(ctx context.Context, key TokenKey) (*AccessToken, error)
+	Save(ctx context.Context, key TokenKey, token *AccessToken) error
+	Delete(ctx context.Context, key TokenKey) error
+}
+
+// WithTokenStore loads and saves the client's tokens with the given store. With a userID the
+// client acts on behalf of that user (see SetUserToken), using the user token from the store.
+// Without one only app tokens are stored, a user token set with WithUserToken or SetUserToken
+// isn't.
+func WithTokenStore(store TokenStore, userID string) Option {
+	return func(c *TwitchClient) {
+		c.tokenStore = store
+		c.tokenUserID = userID
+		if userID != "" {
+			c.userMode = true
+		}
+	}
+}
+
+func (c *TwitchClient) tokenKey() TokenKey {
+	return TokenKey{ClientID: c.clientID, UserID: c.tokenUserID}
+}
+
+// storeKey returns the key of the client's token in the store, or false if the store can't be
+// used: there is none, or the client uses a user token but the store wasn't configured with the
+// user's id (the app token key would mix up user and app tokens).
+func (c *TwitchClient) storeKey() (TokenKey, bool) {
+	if c.tokenStore == nil || (c.tokenUserID == "" && c.usingUserToken()) {
+		return TokenKey{}, false
+	}
+	return c.tokenKey(), true
+}
+
+// loadToken returns the token from the store, or nil if there is none (or no store).
+func (c *TwitchClient) loadToken(ctx context.Context) *AccessToken {
+	key, ok := c.storeKey()
+	if !ok {
+		return nil
+	}
+	t, err := c.tokenStore.Load(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrTokenNotFound) {
+			c.log("tokenstore(%s): failed loading token: %s", key, err)
+		}
+		return nil
+	}
+	return t
+}
+
+func (c *TwitchClient) saveToken(ctx context.Context, t *AccessToken) {
+	key, ok := c.storeKey()
+	if !ok {
+		return
+	}
+	err := c.tokenStore.Save(ctx, key, t)
+	if err != nil {
+		c.log("tokenstore(%s): failed saving token: %s", key, err)
+	}
+}
+
+func (c *TwitchClient) deleteToken(ctx context.Context) {
+	key, ok := c.storeKey()
+	if !ok {
+		return
+	}
+	err := c.tokenStore.Delete(ctx, key)
+	if err != nil {
+		c.log("tokenstore(%s): failed deleting token: %s", key, err)
+	}
+}
+
+// MemoryTokenStore keeps tokens in memory, which only shares them between clients in the same
+// process.
+type MemoryTokenStore struct {
+	mtx    sync.Mutex
+	tokens map[TokenKey]*AccessToken
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[TokenKey]*AccessToken),
+	}
+}
+
+func (s *MemoryTokenStore) Load(ctx context.Context, key TokenKey) (*AccessToken, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	t, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return t, nil
+}
+
+func (s *MemoryTokenStore) Save(ctx context.Context, key TokenKey, token *AccessToken) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.tokens[key] = token
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(ctx context.Context, key TokenKey) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore keeps tokens in a JSON file (optionally encrypted, see
+// NewEncryptedFileTokenStore). The file is re-read on every Load and replaced atomically on
+// every write. Writes hold an exclusive lock on a "<path>.lock" file next to it, so several
+// processes on the same host can share it (on Linux, macOS and the BSDs; elsewhere only a single
+// process may write to it).
+type FileTokenStore struct {
+	path string
+	mtx  sync.Mutex
+	aead cipher.AEAD
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// NewEncryptedFileTokenStore makes a FileTokenStore encrypting the file with AES-GCM. The key
+// must be 16, 24 or 32 bytes long.
+func NewEncryptedFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileTokenStore{path: path, aead: aead}, nil
+}
+
+func (s *FileTokenStore) read() (map[string]*AccessToken, error) {
+	tokens := make(map[string]*AccessToken)
+
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.aead != nil {
+		n := s.aead.NonceSize()
+		if len(b) < n {
+			return nil, errors.New("token file is corrupt")
+		}
+		b, err = s.aead.Open(nil, b[:n], b[n:], nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = json.Unmarshal(b, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *FileTokenStore) write(tokens map[string]*AccessToken) error {
+	b, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		b = s.aead.Seal(nonce, nonce, b, nil)
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+// lock serializes read-modify-write cycles of the file with other processes. The returned func
+// releases the lock.
+func (s *FileTokenStore) lock() (func(), error) {
+	err := os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = lockFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func (s *FileTokenStore) Load(ctx context.Context, key TokenKey) (*AccessToken, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := tokens[key.String()]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return t, nil
+}
+
+func (s *FileTokenStore) Save(ctx context.Context, key TokenKey, token *AccessToken) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[key.String()] = token
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) Delete(ctx context.Context, key TokenKey) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key.String()]; !ok {
+		return nil
+	}
+	delete(tokens, key.String())
+	return s.write(tokens)
+}
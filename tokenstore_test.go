@@ -0,0 +1,147 @@
+package libtwitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStoreRoundTrip(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	plain := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	encrypted, err := NewEncryptedFileTokenStore(filepath.Join(t.TempDir(), "tokens.enc"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	k := TokenKey{ClientID: "client-id", UserID: "1"}
+	token := &AccessToken{
+		AccessToken:  "token",
+		RefreshToken: "refresh",
+		Scopes:       NewScopeSet(ScopeClipsEdit),
+		ExpiresAt:    time.Now().Add(time.Hour).Round(time.Second),
+	}
+
+	for name, store := range map[string]*FileTokenStore{"plain": plain, "encrypted": encrypted} {
+		if _, err := store.Load(ctx, k); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("%s: expected ErrTokenNotFound, got %v", name, err)
+		}
+		if err := store.Save(ctx, k, token); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		got, err := store.Load(ctx, k)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if got.AccessToken != token.AccessToken || got.RefreshToken != token.RefreshToken ||
+			!got.Scopes.Has(ScopeClipsEdit) || !got.ExpiresAt.Equal(token.ExpiresAt) {
+			t.Errorf("%s: expected %+v, got %+v", name, token, got)
+		}
+		if err := store.Delete(ctx, k); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if _, err := store.Load(ctx, k); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("%s: expected ErrTokenNotFound after Delete, got %v", name, err)
+		}
+	}
+
+	if err := encrypted.Save(ctx, k, token); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(encrypted.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) == 0 || b[0] == '{' {
+		t.Error("expected the file to be encrypted")
+	}
+	wrongKey, _ := NewEncryptedFileTokenStore(encrypted.path, []byte("fedcba9876543210fedcba9876543210"))
+	if _, err := wrongKey.Load(ctx, k); err == nil {
+		t.Error("expected decrypting with the wrong key to fail")
+	}
+}
+
+func TestFileTokenStoreSharedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+
+	// Separate stores stand in for separate processes, they only share the file lock.
+	const writers, saves = 8, 10
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(store *FileTokenStore, w int) {
+			defer wg.Done()
+			for i := 0; i < saves; i++ {
+				k := TokenKey{ClientID: "client-id", UserID: fmt.Sprintf("%d-%d", w, i)}
+				if err := store.Save(context.Background(), k, &AccessToken{AccessToken: "token"}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(NewFileTokenStore(path), w)
+	}
+	wg.Wait()
+
+	store := NewFileTokenStore(path)
+	for w := 0; w < writers; w++ {
+		for i := 0; i < saves; i++ {
+			k := TokenKey{ClientID: "client-id", UserID: fmt.Sprintf("%d-%d", w, i)}
+			if _, err := store.Load(context.Background(), k); err != nil {
+				t.Errorf("token %s: %s", k, err)
+			}
+		}
+	}
+}
+
+func TestUserTokenIgnoresAppKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+	appKey := TokenKey{ClientID: "client-id"}
+	appToken := &AccessToken{AccessToken: "app-token", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.Save(ctx, appKey, appToken); err != nil {
+		t.Fatal(err)
+	}
+
+	var appAuth int32
+	s := &tokenServer{api: func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer app-token" {
+			atomic.AddInt32(&appAuth, 1)
+		}
+		userResponse(w, r)
+	}}
+
+	// An app token client sharing the store picks up the stored app token.
+	app := newTestClient(t, s, WithClientSecret("secret"), WithTokenStore(store, ""))
+	if _, err := app.GetUserByID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&appAuth); got != 1 {
+		t.Errorf("expected the app client to use the stored app token, got %d requests with it", got)
+	}
+
+	// A user token client without a store user id must neither use nor overwrite it.
+	user := newTestClient(t, s, WithTokenStore(store, ""), WithUserToken(&AccessToken{
+		AccessToken:  "expired",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}))
+	if _, err := user.GetUserByID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&appAuth); got != 1 {
+		t.Errorf("expected the user client not to send the app token, got %d requests with it", got)
+	}
+	if got := user.UserToken(); got.AccessToken != "token-1" || got.RefreshToken != "refresh-1" {
+		t.Errorf("expected the refreshed user token, got %+v", got)
+	}
+	if got, err := store.Load(ctx, appKey); err != nil || got.AccessToken != "app-token" {
+		t.Errorf("expected the stored app token to be untouched, got %+v %v", got, err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 
 	"github.com/urfave/cli"
 
@@ -15,9 +17,17 @@ import (
 
 var log *logger.Logger = logger.New(os.Stdout, "", 0)
 
-var clientID, clientSecret, webhookCallback string
+var clientID, clientSecret, webhookCallback, tokenFile, userID string
 var debug bool
 
+func defaultTokenFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "libtwitch-token.json"
+	}
+	return filepath.Join(dir, "libtwitch", "token.json")
+}
+
 func printUser(user *libtwitch.User) {
 	log.Printf("name:%s id:%s views:%d type:%s\n", user.Login, user.ID, user.ViewCount, user.BroadcasterType)
 }
@@ -27,7 +37,15 @@ func printGame(game *libtwitch.Game) {
 }
 
 func printStream(stream *libtwitch.Stream) {
-	log.Printf("user:%s game:%s title:%d viewers:%d\n", stream.UserID, stream.GameID, stream.Title, stream.ViewerCount)
+	log.Printf("user:%s game:%s title:%s viewers:%d started:%s\n", stream.UserName, stream.GameName, stream.Title, stream.ViewerCount, stream.StartedAt)
+}
+
+func printChannel(channel *libtwitch.ChannelSearchResult) {
+	if channel.IsLive {
+		log.Printf("name:%s id:%s game:%s title:%s live since:%s\n", channel.BroadcasterLogin, channel.ID, channel.GameName, channel.Title, channel.StartedAt)
+		return
+	}
+	log.Printf("name:%s id:%s game:%s title:%s\n", channel.BroadcasterLogin, channel.ID, channel.GameName, channel.Title)
 }
 
 func makeClient(ctx *cli.Context) *libtwitch.TwitchClient {
@@ -36,7 +54,17 @@ func makeClient(ctx *cli.Context) *libtwitch.TwitchClient {
 		log.Fatal("Missing required argument 'oauth-client-id'")
 	}
 
-	c, err := libtwitch.NewTwitchClient(context.Background(), clientID, clientSecret, webhookCallback, debug)
+	opts := []libtwitch.Option{
+		libtwitch.WithClientSecret(clientSecret),
+		libtwitch.WithCallbackURL(webhookCallback),
+		libtwitch.WithDebug(debug),
+		libtwitch.WithCache(libtwitch.NewLRUCache(1000), libtwitch.DefaultCacheTTLs),
+	}
+	if userID != "" {
+		opts = append(opts, libtwitch.WithTokenStore(libtwitch.NewFileTokenStore(tokenFile), userID))
+	}
+
+	c, err := libtwitch.NewTwitchClient(context.Background(), clientID, opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -69,6 +97,19 @@ func main() {
 			EnvVar:      "LIBTWITCH_CALLBACK_URL",
 			Destination: &webhookCallback,
 		},
+		cli.StringFlag{
+			Name:        "token-file",
+			Usage:       "Where 'login' stores user access tokens.",
+			EnvVar:      "LIBTWITCH_TOKEN_FILE",
+			Value:       defaultTokenFile(),
+			Destination: &tokenFile,
+		},
+		cli.StringFlag{
+			Name:        "user-id",
+			Usage:       "Act as this twitch user, using the token saved by 'login'. (optional)",
+			EnvVar:      "LIBTWITCH_USER_ID",
+			Destination: &userID,
+		},
 		cli.BoolFlag{
 			Name:        "debug",
 			Usage:       "Log requests and debugging info.",
@@ -78,9 +119,13 @@ func main() {
 	}
 
 	app.Commands = []cli.Command{
+		Login,
 		GetUser,
 		GetGame,
 		GetStream,
+		SearchGames,
+		SearchChannels,
+		TopGames,
 		WatchStream,
 	}
 
@@ -90,6 +135,53 @@ func main() {
 	}
 }
 
+var Login = cli.Command{
+	Name:  "login",
+	Usage: "Log in as a twitch user with the device code flow",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "scopes",
+			Usage: "Comma separated list of OAuth scopes to request.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		c := makeClient(ctx)
+
+		var scopes []libtwitch.Scope
+		if ctx.String("scopes") != "" {
+			for _, scope := range strings.Split(ctx.String("scopes"), ",") {
+				scopes = append(scopes, libtwitch.Scope(scope))
+			}
+		}
+
+		dc, err := c.StartDeviceAuthorization(context.Background(), scopes)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+
+		fmt.Printf("To log in, visit %s and enter the code: %s\n", dc.VerificationURI, dc.UserCode)
+
+		token, err := c.PollDeviceToken(context.Background(), dc)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+
+		c.SetUserToken(token)
+		v, err := c.ValidateToken(context.Background())
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+
+		store := libtwitch.NewFileTokenStore(tokenFile)
+		err = store.Save(context.Background(), libtwitch.TokenKey{ClientID: clientID, UserID: v.UserID}, token)
+		if err != nil {
+			log.Fatalf("Error saving token: %s", err)
+		}
+		log.Printf("Logged in as %s, token saved to %s (use --user-id %s)\n", v.Login, tokenFile, v.UserID)
+		return nil
+	},
+}
+
 var GetUser = cli.Command{
 	Name:  "get-user",
 	Usage: "Get user",
@@ -102,7 +194,7 @@ var GetUser = cli.Command{
 
 		userName := ctx.Args()[0]
 
-		user, err := c.GetUserByName(userName)
+		user, err := c.GetUserByName(context.Background(), userName)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
 		}
@@ -123,7 +215,7 @@ var GetGame = cli.Command{
 
 		gameName := ctx.Args()[0]
 
-		game, err := c.GetGameByName(gameName)
+		game, err := c.GetGameByName(context.Background(), gameName)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
 		}
@@ -144,7 +236,7 @@ var GetStream = cli.Command{
 
 		userName := ctx.Args()[0]
 
-		stream, err := c.GetStreamByUserName(userName)
+		stream, err := c.GetStreamByUserName(context.Background(), userName)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
 		}
@@ -153,6 +245,90 @@ var GetStream = cli.Command{
 	},
 }
 
+var SearchGames = cli.Command{
+	Name:  "search-games",
+	Usage: "Search games and categories",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of results.",
+			Value: 20,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		c := makeClient(ctx)
+
+		if len(ctx.Args()) != 1 {
+			log.Fatal("Missing required argument: query")
+		}
+
+		games, err := c.SearchCategories(context.Background(), ctx.Args()[0]).All(context.Background(), ctx.Int("limit"))
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		for _, game := range games {
+			printGame(game)
+		}
+		return nil
+	},
+}
+
+var SearchChannels = cli.Command{
+	Name:  "search-channels",
+	Usage: "Search channels",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of results.",
+			Value: 20,
+		},
+		cli.BoolFlag{
+			Name:  "live",
+			Usage: "Only return live channels.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		c := makeClient(ctx)
+
+		if len(ctx.Args()) != 1 {
+			log.Fatal("Missing required argument: query")
+		}
+
+		channels, err := c.SearchChannels(context.Background(), ctx.Args()[0], ctx.Bool("live")).All(context.Background(), ctx.Int("limit"))
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		for _, channel := range channels {
+			printChannel(channel)
+		}
+		return nil
+	},
+}
+
+var TopGames = cli.Command{
+	Name:  "top-games",
+	Usage: "List games by current viewers",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of results.",
+			Value: 20,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		c := makeClient(ctx)
+
+		games, err := c.GetTopGames(context.Background()).All(context.Background(), ctx.Int("limit"))
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		for i, game := range games {
+			log.Printf("%d. name:%s id:%s igdb:%s\n", i+1, game.Name, game.ID, game.IGDBID)
+		}
+		return nil
+	},
+}
+
 var WatchStream = cli.Command{
 	Name:  "watch-stream",
 	Usage: "Watch stream up/down events for user",
@@ -169,7 +345,7 @@ var WatchStream = cli.Command{
 
 		userName := ctx.Args()[0]
 
-		user, err := c.GetUserByName(userName)
+		user, err := c.GetUserByName(context.Background(), userName)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
 		}
@@ -179,12 +355,12 @@ var WatchStream = cli.Command{
 
 		go http.ListenAndServe(":9876", nil)
 
-		sw, err := c.WatchStream(user.ID)
+		sw, err := c.WatchStream(context.Background(), user.ID)
 		if err != nil {
 			return err
 		}
 
-		fw, err := c.WatchFollows(user.ID)
+		fw, err := c.WatchFollows(context.Background(), user.ID)
 		if err != nil {
 			return err
 		}
@@ -197,12 +373,12 @@ var WatchStream = cli.Command{
 
 				fmt.Printf("Got follow event: %+v\n", follow)
 
-				from, err := c.GetUserByID(follow.FromID)
+				from, err := c.GetUserByID(context.Background(), follow.FromID)
 				if err != nil {
 					fmt.Printf("Error fetching user: %s\n", err.Error())
 				}
 
-				to, err := c.GetUserByID(follow.ToID)
+				to, err := c.GetUserByID(context.Background(), follow.ToID)
 				if err != nil {
 					fmt.Printf("Error fetching user: %s\n", err.Error())
 				}
@@ -218,7 +394,7 @@ var WatchStream = cli.Command{
 					continue
 				}
 
-				game, err := c.GetGameByID(stream.GameID)
+				game, err := c.GetGameByID(context.Background(), stream.GameID)
 				if err != nil {
 					fmt.Printf("Error fetching game: %s\n", err.Error())
 				}
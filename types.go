@@ -0,0 +1,120 @@
+package libtwitch
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The enums below are strings under the hood, so values twitch adds later still round-trip; use
+// Known to check for them.
+
+type BroadcasterType string
+
+const (
+	BroadcasterTypeNone      BroadcasterType = ""
+	BroadcasterTypeAffiliate BroadcasterType = "affiliate"
+	BroadcasterTypePartner   BroadcasterType = "partner"
+)
+
+func (t BroadcasterType) Known() bool {
+	switch t {
+	case BroadcasterTypeNone, BroadcasterTypeAffiliate, BroadcasterTypePartner:
+		return true
+	}
+	return false
+}
+
+func (t *BroadcasterType) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalEnum(b)
+	*t = BroadcasterType(s)
+	return err
+}
+
+type UserType string
+
+const (
+	UserTypeNormal    UserType = ""
+	UserTypeAdmin     UserType = "admin"
+	UserTypeGlobalMod UserType = "global_mod"
+	UserTypeStaff     UserType = "staff"
+)
+
+func (t UserType) Known() bool {
+	switch t {
+	case UserTypeNormal, UserTypeAdmin, UserTypeGlobalMod, UserTypeStaff:
+		return true
+	}
+	return false
+}
+
+func (t *UserType) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalEnum(b)
+	*t = UserType(s)
+	return err
+}
+
+type StreamType string
+
+const (
+	// StreamTypeNone is reported when a stream ended (or on errors).
+	StreamTypeNone StreamType = ""
+	StreamTypeLive StreamType = "live"
+
+	// StreamTypeAll selects streams of any type in a StreamsQuery. Twitch never reports it.
+	StreamTypeAll StreamType = "all"
+)
+
+func (t StreamType) Known() bool {
+	switch t {
+	case StreamTypeNone, StreamTypeLive:
+		return true
+	}
+	return false
+}
+
+func (t *StreamType) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalEnum(b)
+	*t = StreamType(s)
+	return err
+}
+
+// unmarshalEnum decodes a JSON string enum, treating null as the empty value.
+func unmarshalEnum(b []byte) (string, error) {
+	if string(b) == "null" {
+		return "", nil
+	}
+	var s string
+	err := json.Unmarshal(b, &s)
+	return s, err
+}
+
+// parseOptionalTime parses an RFC 3339 timestamp, returning the zero time for the empty string
+// twitch sends for timestamps that don't apply (e.g. started_at of offline channels).
+func parseOptionalTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+type VideoType string
+
+const (
+	VideoTypeArchive   VideoType = "archive"
+	VideoTypeHighlight VideoType = "highlight"
+	VideoTypeUpload    VideoType = "upload"
+)
+
+func (t VideoType) Known() bool {
+	switch t {
+	case VideoTypeArchive, VideoTypeHighlight, VideoTypeUpload:
+		return true
+	}
+	return false
+}
+
+func (t *VideoType) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalEnum(b)
+	*t = VideoType(s)
+	return err
+}
@@ -0,0 +1,145 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxDeleteVideos is the most videos helix deletes in a single request.
+const maxDeleteVideos = 5
+
+type Video struct {
+	ID            string         `json:"id"`
+	StreamID      string         `json:"stream_id"` // Only set for archives
+	UserID        string         `json:"user_id"`
+	UserLogin     string         `json:"user_login"`
+	UserName      string         `json:"user_name"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	CreatedAt     time.Time      `json:"created_at"`
+	PublishedAt   time.Time      `json:"published_at"`
+	URL           string         `json:"url"`
+	ThumbnailURL  string         `json:"thumbnail_url"`
+	Viewable      string         `json:"viewable"`
+	ViewCount     int            `json:"view_count"`
+	Language      string         `json:"language"`
+	Type          VideoType      `json:"type"`
+	Duration      time.Duration  `json:"duration"`
+	MutedSegments []MutedSegment `json:"muted_segments"`
+}
+
+// MutedSegment is a part of a video muted for copyrighted audio, in seconds from the start.
+type MutedSegment struct {
+	Offset   int `json:"offset"`
+	Duration int `json:"duration"`
+}
+
+func (v *Video) UnmarshalJSON(b []byte) error {
+	type alias Video
+	aux := struct {
+		*alias
+		StreamID *string `json:"stream_id"`
+		Duration string  `json:"duration"`
+	}{alias: (*alias)(v)}
+
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+	if aux.StreamID != nil {
+		v.StreamID = *aux.StreamID
+	}
+	v.Duration = 0
+	if aux.Duration != "" {
+		// e.g. "3h8m33s"
+		v.Duration, err = time.ParseDuration(aux.Duration)
+	}
+	return err
+}
+
+// MarshalJSON writes Duration in twitch's format, so videos round-trip through UnmarshalJSON.
+func (v Video) MarshalJSON() ([]byte, error) {
+	type alias Video
+	return json.Marshal(struct {
+		alias
+		Duration string `json:"duration"`
+	}{alias: alias(v), Duration: v.Duration.String()})
+}
+
+// VideosQuery selects the videos GetVideos returns. Exactly one of IDs, UserID or GameID must be
+// set; the other filters only apply to user and game lookups.
+type VideosQuery struct {
+	IDs      []string
+	UserID   string
+	GameID   string
+	Language string
+	Period   string    // "all" (default), "day", "week" or "month"
+	Sort     string    // "time" (default), "trending" or "views"
+	Type     VideoType // Empty for all types
+	First    int       // Page size, up to 100
+	After    string    // Cursor to resume from, see Paginator.Cursor
+}
+
+func (q VideosQuery) values() url.Values {
+	v := url.Values{}
+	if len(q.IDs) > 0 {
+		v["id"] = q.IDs
+	}
+	if q.UserID != "" {
+		v.Set("user_id", q.UserID)
+	}
+	if q.GameID != "" {
+		v.Set("game_id", q.GameID)
+	}
+	if q.Language != "" {
+		v.Set("language", q.Language)
+	}
+	if q.Period != "" {
+		v.Set("period", q.Period)
+	}
+	if q.Sort != "" {
+		v.Set("sort", q.Sort)
+	}
+	if q.Type != "" {
+		v.Set("type", string(q.Type))
+	}
+	if q.First > 0 {
+		v.Set("first", strconv.Itoa(q.First))
+	}
+	return v
+}
+
+// GetVideos lists videos matching the query.
+func (c *TwitchClient) GetVideos(ctx context.Context, q VideosQuery) *Paginator[*Video] {
+	p := newPaginator[*Video](c, "videos", q.values()).bind(ctx)
+	p.cursor = q.After
+	return p
+}
+
+// DeleteVideos deletes videos of the token's user and returns the ids that were deleted. It
+// requires a user token with the channel:manage:videos scope.
+func (c *TwitchClient) DeleteVideos(ctx context.Context, ids ...string) ([]string, error) {
+	deleted := []string{}
+	for start := 0; start < len(ids); start += maxDeleteVideos {
+		end := start + maxDeleteVideos
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		params := &url.Values{"id": ids[start:end]}
+		_, body, err := c.RequestContext(ctx, "DELETE", "videos", params, nil)
+		if err != nil {
+			return deleted, NewTwitchClientError("error making request", err)
+		}
+
+		chunk, err := decodeData[string](body)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, chunk...)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,97 @@
+package libtwitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVideoUnmarshal(t *testing.T) {
+	var videos []*Video
+	err := json.Unmarshal([]byte(`[
+		{"id":"1","stream_id":null,"type":"upload","duration":"3h8m33s","created_at":"2021-01-01T00:00:00Z","muted_segments":null},
+		{"id":"2","stream_id":"42","type":"archive","duration":"45s","muted_segments":[{"duration":30,"offset":120},{"duration":60,"offset":600}]}
+	]`), &videos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := videos[0]
+	if v.StreamID != "" || v.Type != VideoTypeUpload || len(v.MutedSegments) != 0 {
+		t.Errorf("unexpected video: %+v", v)
+	}
+	if want := 3*time.Hour + 8*time.Minute + 33*time.Second; v.Duration != want {
+		t.Errorf("expected duration %s, got %s", want, v.Duration)
+	}
+	if !v.CreatedAt.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected created_at: %s", v.CreatedAt)
+	}
+
+	v = videos[1]
+	if v.StreamID != "42" || v.Duration != 45*time.Second {
+		t.Errorf("unexpected video: %+v", v)
+	}
+	want := []MutedSegment{{Offset: 120, Duration: 30}, {Offset: 600, Duration: 60}}
+	if fmt.Sprint(v.MutedSegments) != fmt.Sprint(want) {
+		t.Errorf("expected muted segments %v, got %v", want, v.MutedSegments)
+	}
+
+	if err := json.Unmarshal([]byte(`{"duration":"forever"}`), &Video{}); err == nil {
+		t.Error("expected an invalid duration to fail")
+	}
+}
+
+func TestVideoRoundTrip(t *testing.T) {
+	v := &Video{ID: "1", StreamID: "42", Type: VideoTypeArchive, Duration: 90 * time.Minute,
+		MutedSegments: []MutedSegment{{Offset: 1, Duration: 2}}}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"duration":"1h30m0s"`) {
+		t.Errorf("expected twitch's duration format, got %s", b)
+	}
+
+	got := &Video{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(v) {
+		t.Errorf("expected %+v, got %+v", v, got)
+	}
+}
+
+func TestDeleteVideosChunks(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		ids := r.URL.Query()["id"]
+		if r.Method != "DELETE" || len(ids) > maxDeleteVideos {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := json.Marshal(ids)
+		fmt.Fprintf(w, `{"data":%s}`, b)
+	}), WithUserToken(&AccessToken{AccessToken: "token", Scopes: NewScopeSet(ScopeChannelManageVideos)}))
+
+	var ids []string
+	for i := 0; i < 12; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	deleted, err := c.DeleteVideos(context.Background(), ids...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(deleted, ",") != strings.Join(ids, ",") {
+		t.Errorf("expected all videos to be deleted, got %v", deleted)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
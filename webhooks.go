@@ -18,17 +18,17 @@ func makeTopicKey(topic, userID string) string {
 	return fmt.Sprintf("%s/%s", topic, userID)
 }
 
-func makeTopicURL(topic, userID string) (string, error) {
+func makeTopicURL(endpoint, topic, userID string) (string, error) {
 	switch topic {
 	case "streams":
 		v := &url.Values{"user_id": []string{userID}}
-		return fmt.Sprintf("https://api.twitch.tv/helix/streams?%s", v.Encode()), nil
+		return fmt.Sprintf("%sstreams?%s", endpoint, v.Encode()), nil
 	case "follows":
 		v := &url.Values{
 			"first": []string{"1"},
 			"to_id": []string{userID},
 		}
-		return fmt.Sprintf("https://api.twitch.tv/helix/users/follows?%s", v.Encode()), nil
+		return fmt.Sprintf("%susers/follows?%s", endpoint, v.Encode()), nil
 	default:
 		return "", errors.New("invalid topic")
 	}
@@ -210,7 +210,7 @@ func (sw *StreamWatcher) resub() {
 		select {
 		case <-t.C:
 			sw.client.log("streamwatcher(%s): re-subscribing to topic", sw.topicKey())
-			err := sw.sub()
+			err := sw.sub(sw.ctx)
 			if err != nil {
 				sw.client.log("streamwatcher(%s): error re-subscribing to topic: %s", sw.topicKey(), err.Error())
 			} else {
@@ -224,9 +224,9 @@ func (sw *StreamWatcher) resub() {
 	}
 }
 
-func (sw *StreamWatcher) sub() error {
+func (sw *StreamWatcher) sub(ctx context.Context) error {
 
-	topicURL, err := makeTopicURL(sw.topic, sw.userID)
+	topicURL, err := makeTopicURL(sw.client.endpoint, sw.topic, sw.userID)
 	if err != nil {
 		return err
 	}
@@ -244,7 +244,8 @@ func (sw *StreamWatcher) sub() error {
 		Secret:   sw.client.callbackSecret,
 	}
 
-	resp, _, err := sw.client.Request("POST", "webhooks/hub", nil, sub)
+	// (Re)subscribing to the same topic just renews the lease, so it is safe to retry.
+	resp, _, err := sw.client.requestRaw(ctx, "POST", "webhooks/hub", nil, sub, true)
 	if err != nil {
 		return NewTwitchClientError("error making request", err)
 	}
@@ -252,7 +253,6 @@ func (sw *StreamWatcher) sub() error {
 	if resp.StatusCode != http.StatusAccepted {
 		return NewTwitchClientError(fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil)
 	}
-
 	return nil
 }
 
@@ -277,7 +277,7 @@ func (sw *StreamWatcher) Follows() <-chan *Follow {
 	return sw.follows
 }
 
-func (c *TwitchClient) addStreamWatcher(topic, userID string) (*StreamWatcher, error) {
+func (c *TwitchClient) addStreamWatcher(ctx context.Context, topic, userID string) (*StreamWatcher, error) {
 	c.streamWatchersMtx.Lock()
 
 	_, ok := c.streamWatchers[makeTopicKey(topic, userID)]
@@ -288,9 +288,9 @@ func (c *TwitchClient) addStreamWatcher(topic, userID string) (*StreamWatcher, e
 		return nil, errors.New("already subscribed to topic")
 	}
 
-	ctx, cancel := context.WithCancel(c.ctx)
+	wctx, cancel := context.WithCancel(c.ctx)
 	sw := &StreamWatcher{
-		ctx:    ctx,
+		ctx:    wctx,
 		cancel: cancel,
 
 		client:  c,
@@ -300,11 +300,11 @@ func (c *TwitchClient) addStreamWatcher(topic, userID string) (*StreamWatcher, e
 		topic:  topic,
 		userID: userID,
 
-		lease: 10 * time.Minute,
+		lease: c.lease,
 	}
 	c.streamWatchers[sw.topicKey()] = sw
 
-	err := sw.sub()
+	err := sw.sub(ctx)
 	if err != nil {
 		c.streamWatchersMtx.Unlock()
 		c.removeStreamWatcher(sw.topicKey())
@@ -331,12 +331,15 @@ func (c *TwitchClient) removeStreamWatcher(topicKey string) error {
 // TODO: Ew. I hate this now. The first time a caller subs to something we should do the API
 // sub dance, and then store a slice of channels for all the subsequent callers to recieve
 // events on. This works well enough for now.
-func (c *TwitchClient) WatchStream(userID string) (*StreamWatcher, error) {
-	return c.addStreamWatcher("streams", userID)
+//
+// ctx only bounds the initial subscription request, the watcher lives until it is closed or the
+// client is.
+func (c *TwitchClient) WatchStream(ctx context.Context, userID string) (*StreamWatcher, error) {
+	return c.addStreamWatcher(ctx, "streams", userID)
 }
 
-func (c *TwitchClient) WatchFollows(userID string) (*StreamWatcher, error) {
-	return c.addStreamWatcher("follows", userID)
+func (c *TwitchClient) WatchFollows(ctx context.Context, userID string) (*StreamWatcher, error) {
+	return c.addStreamWatcher(ctx, "follows", userID)
 }
 
 type NilStreamWatcher struct {}
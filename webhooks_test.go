@@ -0,0 +1,30 @@
+package libtwitch
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscribeRetryAttempts(t *testing.T) {
+	var posts int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == "/webhooks/hub" {
+			atomic.AddInt32(&posts, 1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), WithCallbackURL("http://localhost/twitch"), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:        3,
+		BaseDelay:          time.Millisecond,
+		RetryNonIdempotent: true,
+	}))
+
+	if _, err := c.WatchStream(context.Background(), "1"); err == nil {
+		t.Fatal("expected subscribing to fail")
+	}
+	if got := atomic.LoadInt32(&posts); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}